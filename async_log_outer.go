@@ -0,0 +1,207 @@
+package golog
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// An OverflowPolicy controls what an asynchronous LogOuter does with a
+// LogMessage when its queue is full.
+type OverflowPolicy int
+
+const (
+	// Wait for room in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// Drop the LogMessage being output.
+	OverflowDropNewest
+	// Drop the oldest LogMessage in the queue to make room.
+	OverflowDropOldest
+	// Drop the LogMessage being output if it is below
+	// AsyncOptions.DropBelow, otherwise wait for room in the queue.
+	OverflowDropBelowLevel
+)
+
+// Options controlling an asynchronous LogOuter.
+type AsyncOptions struct {
+	// The number of LogMessages that can be queued before the
+	// OverflowPolicy applies. If zero, defaults to 1024.
+	QueueSize int
+	Overflow  OverflowPolicy
+	// Only used by OverflowDropBelowLevel.
+	DropBelow int
+}
+
+const defaultAsyncQueueSize = 1024
+
+// An AsyncLogOuter is a LogOuter that queues LogMessages and outputs them
+// from a background goroutine, so callers of Output are not blocked by a
+// slow LogOuter. Flush waits for the queue to drain and then flushes the
+// wrapped LogOuter. Close drains the queue, stops the background goroutine,
+// and closes the wrapped LogOuter.
+type AsyncLogOuter interface {
+	LogOuter
+	Flusher
+	io.Closer
+	// Returns the number of LogMessages dropped due to the OverflowPolicy
+	// or due to being output after Close.
+	Dropped() uint64
+}
+
+type asyncLogOuter struct {
+	// Accessed atomically, so first in the struct to ensure alignment.
+	dropped uint64
+	outer   LogOuter
+	options AsyncOptions
+	queue   chan *LogMessage
+	// Closed when the background goroutine exits.
+	exited chan bool
+
+	// Protects pending, the number of LogMessages accepted but not yet
+	// output. Signals drained when pending reaches zero.
+	lock    sync.Mutex
+	drained *sync.Cond
+	pending int
+
+	// Held for reading while sending to the queue, so that Close doesn't
+	// close the queue out from under a sender.
+	closeLock sync.RWMutex
+	closed    bool
+}
+
+// Returns an AsyncLogOuter wrapping the LogOuter. For example,
+//	outer, _ := golog.NewFileLogOuter("foo.log")
+//	golog.AddLogOuter("foo", golog.NewAsyncLogOuter(outer, golog.AsyncOptions{
+//		Overflow:  golog.OverflowDropBelowLevel,
+//		DropBelow: golog.ERROR,
+//	}))
+func NewAsyncLogOuter(outer LogOuter, options AsyncOptions) AsyncLogOuter {
+	if options.QueueSize <= 0 {
+		options.QueueSize = defaultAsyncQueueSize
+	}
+
+	ret := &asyncLogOuter{
+		outer:   outer,
+		options: options,
+		queue:   make(chan *LogMessage, options.QueueSize),
+		exited:  make(chan bool),
+	}
+	ret.drained = sync.NewCond(&ret.lock)
+
+	go ret.run()
+	return ret
+}
+
+// Outputs LogMessages from the queue until it is closed.
+func (o *asyncLogOuter) run() {
+	for m := range o.queue {
+		o.outer.Output(m)
+		o.done()
+	}
+	close(o.exited)
+}
+
+// Marks a LogMessage as no longer pending.
+func (o *asyncLogOuter) done() {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.pending--
+	if o.pending == 0 {
+		o.drained.Broadcast()
+	}
+}
+
+// Marks a pending LogMessage as dropped.
+func (o *asyncLogOuter) drop() {
+	atomic.AddUint64(&o.dropped, 1)
+	o.done()
+}
+
+func (o *asyncLogOuter) Output(m *LogMessage) {
+	o.closeLock.RLock()
+	defer o.closeLock.RUnlock()
+
+	if o.closed {
+		atomic.AddUint64(&o.dropped, 1)
+		return
+	}
+
+	o.lock.Lock()
+	o.pending++
+	o.lock.Unlock()
+
+	switch o.options.Overflow {
+	case OverflowDropNewest:
+		o.sendOrDrop(m)
+	case OverflowDropBelowLevel:
+		if m.Level < o.options.DropBelow {
+			o.sendOrDrop(m)
+		} else {
+			o.queue <- m
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case o.queue <- m:
+				return
+			default:
+			}
+			// The queue is full, drop the oldest message to make
+			// room. We may race with the background goroutine, in
+			// which case there is nothing to drop and we retry.
+			select {
+			case <-o.queue:
+				o.drop()
+			default:
+			}
+		}
+	default:
+		o.queue <- m
+	}
+}
+
+// Sends the LogMessage to the queue if there is room, otherwise drops it.
+func (o *asyncLogOuter) sendOrDrop(m *LogMessage) {
+	select {
+	case o.queue <- m:
+	default:
+		o.drop()
+	}
+}
+
+// Implements Flusher.Flush(). Waits until the queue is empty and every queued
+// LogMessage has been output, then flushes the wrapped LogOuter if it is a
+// Flusher.
+func (o *asyncLogOuter) Flush() error {
+	o.lock.Lock()
+	for o.pending > 0 {
+		o.drained.Wait()
+	}
+	o.lock.Unlock()
+
+	if flusher, ok := o.outer.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// Implements io.Closer.Close().
+func (o *asyncLogOuter) Close() error {
+	o.closeLock.Lock()
+	if o.closed {
+		o.closeLock.Unlock()
+		return nil
+	}
+	o.closed = true
+	close(o.queue)
+	o.closeLock.Unlock()
+
+	<-o.exited
+	return closeLogOuter(o.outer)
+}
+
+// Implements AsyncLogOuter.Dropped().
+func (o *asyncLogOuter) Dropped() uint64 {
+	return atomic.LoadUint64(&o.dropped)
+}
@@ -0,0 +1,185 @@
+package golog
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// A LogOuter that records messages, signalling started then blocking until
+// released.
+type blockingLogOuter struct {
+	started chan bool
+	release chan bool
+	lock    sync.Mutex
+	output  []string
+}
+
+func (o *blockingLogOuter) Output(m *LogMessage) {
+	o.started <- true
+	<-o.release
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.output = append(o.output, m.Message)
+}
+
+func TestAsyncLogOuterDropNewest(t *testing.T) {
+	outer := &blockingLogOuter{
+		started: make(chan bool, 3),
+		release: make(chan bool),
+	}
+	async := NewAsyncLogOuter(outer, AsyncOptions{
+		QueueSize: 1,
+		Overflow:  OverflowDropNewest,
+	})
+
+	// The background goroutine blocks on the first message, the second
+	// fills the queue, and the third is dropped.
+	async.Output(&LogMessage{Message: "first"})
+	<-outer.started
+	async.Output(&LogMessage{Message: "second"})
+	async.Output(&LogMessage{Message: "third"})
+	close(outer.release)
+
+	if err := async.Flush(); err != nil {
+		t.Error("Error flushing:", err)
+	}
+	if async.Dropped() != 1 {
+		t.Errorf("Expected 1 dropped message, got %d", async.Dropped())
+	}
+	if len(outer.output) != 2 || outer.output[1] != "second" {
+		t.Errorf("Expected [first second], got %v", outer.output)
+	}
+
+	async.Close()
+	async.Output(&LogMessage{Message: "closed"})
+	if async.Dropped() != 2 {
+		t.Errorf("Expected 2 dropped messages, got %d", async.Dropped())
+	}
+}
+
+// Waits until the AsyncLogOuter has accepted n LogMessages that have not yet
+// been output, so that senders blocked on a full queue have been counted.
+func waitPending(async AsyncLogOuter, n int) {
+	o := async.(*asyncLogOuter)
+	for {
+		o.lock.Lock()
+		pending := o.pending
+		o.lock.Unlock()
+		if pending == n {
+			return
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestAsyncLogOuterDropOldest(t *testing.T) {
+	outer := &blockingLogOuter{
+		started: make(chan bool, 3),
+		release: make(chan bool),
+	}
+	async := NewAsyncLogOuter(outer, AsyncOptions{
+		QueueSize: 1,
+		Overflow:  OverflowDropOldest,
+	})
+
+	// The background goroutine blocks on the first message, the second
+	// fills the queue, and the third replaces the second.
+	async.Output(&LogMessage{Message: "first"})
+	<-outer.started
+	async.Output(&LogMessage{Message: "second"})
+	async.Output(&LogMessage{Message: "third"})
+	close(outer.release)
+
+	if err := async.Flush(); err != nil {
+		t.Error("Error flushing:", err)
+	}
+	if async.Dropped() != 1 {
+		t.Errorf("Expected 1 dropped message, got %d", async.Dropped())
+	}
+	if len(outer.output) != 2 || outer.output[1] != "third" {
+		t.Errorf("Expected [first third], got %v", outer.output)
+	}
+	async.Close()
+}
+
+func TestAsyncLogOuterDropBelowLevel(t *testing.T) {
+	outer := &blockingLogOuter{
+		started: make(chan bool, 4),
+		release: make(chan bool),
+	}
+	async := NewAsyncLogOuter(outer, AsyncOptions{
+		QueueSize: 1,
+		Overflow:  OverflowDropBelowLevel,
+		DropBelow: ERROR,
+	})
+
+	// The background goroutine blocks on the first message, the second
+	// fills the queue, the third is dropped, and the fourth waits.
+	async.Output(&LogMessage{Level: INFO, Message: "first"})
+	<-outer.started
+	async.Output(&LogMessage{Level: INFO, Message: "second"})
+	async.Output(&LogMessage{Level: WARNING, Message: "third"})
+	go async.Output(&LogMessage{Level: ERROR, Message: "fourth"})
+	waitPending(async, 3)
+	close(outer.release)
+
+	if err := async.Flush(); err != nil {
+		t.Error("Error flushing:", err)
+	}
+	if async.Dropped() != 1 {
+		t.Errorf("Expected 1 dropped message, got %d", async.Dropped())
+	}
+	if len(outer.output) != 3 || outer.output[2] != "fourth" {
+		t.Errorf("Expected [first second fourth], got %v", outer.output)
+	}
+	async.Close()
+}
+
+func TestAsyncLogOuterBlock(t *testing.T) {
+	outer := &blockingLogOuter{
+		started: make(chan bool, 3),
+		release: make(chan bool),
+	}
+	async := NewAsyncLogOuter(outer, AsyncOptions{
+		QueueSize: 1,
+		Overflow:  OverflowBlock,
+	})
+
+	// The background goroutine blocks on the first message, the second
+	// fills the queue, and the third waits for room.
+	async.Output(&LogMessage{Message: "first"})
+	<-outer.started
+	async.Output(&LogMessage{Message: "second"})
+	sent := make(chan bool)
+	go func() {
+		async.Output(&LogMessage{Message: "third"})
+		close(sent)
+	}()
+	waitPending(async, 3)
+
+	// Flush must wait for the blocked sender as well as the queue.
+	flushed := make(chan error)
+	go func() { flushed <- async.Flush() }()
+
+	select {
+	case <-sent:
+		t.Error("Output returned while the queue was full")
+	case <-flushed:
+		t.Error("Flush returned while messages were pending")
+	default:
+	}
+
+	close(outer.release)
+	<-sent
+	if err := <-flushed; err != nil {
+		t.Error("Error flushing:", err)
+	}
+	if async.Dropped() != 0 {
+		t.Errorf("Expected no dropped messages, got %d", async.Dropped())
+	}
+	if len(outer.output) != 3 || outer.output[2] != "third" {
+		t.Errorf("Expected [first second third], got %v", outer.output)
+	}
+	async.Close()
+}
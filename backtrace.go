@@ -0,0 +1,122 @@
+package golog
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"path"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The set of locations, as "file.go:line", at which a log statement that
+// fires appends the stack trace of the goroutine to the message.
+type backtraceAtFlag struct {
+	lock      sync.RWMutex
+	locations map[string]bool
+}
+
+var backtraceAt = &backtraceAtFlag{locations: make(map[string]bool)}
+
+func init() {
+	flag.Var(backtraceAt, "golog.log_backtrace_at",
+		"Comma separated list of file.go:line locations. When a log "+
+			"statement at one of these locations is logged, the "+
+			"stack trace of the goroutine is appended to the message")
+}
+
+func (b *backtraceAtFlag) Set(val string) bool {
+	locations := make(map[string]bool)
+
+	for _, location := range strings.Split(val, ",") {
+		if location == "" {
+			continue
+		}
+		colon := strings.LastIndex(location, ":")
+		if colon <= 0 {
+			fmt.Println("Error setting flag: expected file.go:line, got",
+				location)
+			return false
+		}
+		if _, err := strconv.Atoi(location[colon+1:]); err != nil {
+			fmt.Println("Error setting flag: ", err)
+			return false
+		}
+		locations[location] = true
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.locations = locations
+	return true
+}
+
+func (b *backtraceAtFlag) String() string {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	locations := make([]string, 0, len(b.locations))
+	for location, _ := range b.locations {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+	return strings.Join(locations, ",")
+}
+
+// Returns the full "file:line" of the caller skip frames up and whether it is
+// at one of the locations. Skip 0 refers to the function calling this
+// function. Cheap if there are no locations.
+func (b *backtraceAtFlag) at(skip int) (string, bool) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	if len(b.locations) == 0 {
+		return "", false
+	}
+	if _, file, line, ok := runtime.Caller(skip + 1); ok {
+		location := file + ":" + strconv.Itoa(line)
+		if b.locations[path.Base(location)] {
+			return location, true
+		}
+	}
+	return "", false
+}
+
+// Returns the stack trace of the current goroutine, without the frames above
+// the one at location (as returned by at()). Returns the whole trace if no
+// frame is at location.
+func callerStackTrace(location string) []byte {
+	trace := stackTrace(false)
+
+	// The trace is a header line followed by two lines per frame, the
+	// function and then a tab and the "file:line +offset" of the frame.
+	lines := bytes.SplitAfter(trace, []byte("\n"))
+	for i := 2; i < len(lines); i += 2 {
+		frame := bytes.TrimRight(lines[i], "\n")
+		if bytes.Equal(frame, []byte("\t"+location)) ||
+			bytes.HasPrefix(frame, []byte("\t"+location+" ")) {
+			trimmed := append([][]byte{lines[0]}, lines[i-1:]...)
+			return bytes.Join(trimmed, nil)
+		}
+	}
+	return trace
+}
+
+// Returns the stack trace of the current goroutine, or of all goroutines if
+// all is true.
+func stackTrace(all bool) []byte {
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, all)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	panic("Code never reaches here, this mollifies the compiler.")
+}
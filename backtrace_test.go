@@ -0,0 +1,35 @@
+package golog
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBacktraceAt(t *testing.T) {
+	logger, received := newChanLogger(2, INFO, NoLocation)
+
+	_, _, line, _ := runtime.Caller(0)
+	if !backtraceAt.Set(fmt.Sprintf("backtrace_test.go:%d", line+6)) {
+		t.Fatal("Error setting log_backtrace_at")
+	}
+	defer backtraceAt.Set("")
+
+	logger.Info("with backtrace")
+	logger.Info("without backtrace")
+
+	m := receive(t, received)
+	lines := strings.Split(m.Message, "\n")
+	if len(lines) < 3 || lines[0] != "with backtrace" ||
+		!strings.HasPrefix(lines[1], "goroutine ") {
+		t.Fatalf("Expected stack trace, got %q", m.Message)
+	}
+	// The first frame is the caller, not golog itself.
+	if !strings.HasPrefix(lines[2], testPackage+".TestBacktraceAt(") {
+		t.Errorf("Expected first frame TestBacktraceAt, got %q", m.Message)
+	}
+	if m := receive(t, received); m.Message != "without backtrace" {
+		t.Errorf("Expected without backtrace, got %q", m.Message)
+	}
+}
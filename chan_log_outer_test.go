@@ -0,0 +1,37 @@
+package golog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// The import path of this package, which depends on how it is built.
+var testPackage = reflect.TypeOf(LogMessage{}).PkgPath()
+
+// A LogOuter that sends every LogMessage on a channel.
+type chanLogOuter chan *LogMessage
+
+func (c chanLogOuter) Output(m *LogMessage) {
+	c <- m
+}
+
+func receive(t *testing.T, c chanLogOuter) *LogMessage {
+	select {
+	case m := <-c:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for LogMessage")
+	}
+	return nil
+}
+
+// Returns a PackageLogger with the minloglevel and metadataFunc that outputs
+// to the returned chanLogOuter, which buffers up to size LogMessages.
+func newChanLogger(size int, minloglevel int,
+	metadataFunc MetadataFunc) (*PackageLogger, chanLogOuter) {
+	received := make(chanLogOuter, size)
+	multi := NewMultiLogOuter()
+	multi.AddLogOuter("chan", received)
+	return NewPackageLogger(multi, minloglevel, nil, metadataFunc), received
+}
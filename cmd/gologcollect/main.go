@@ -0,0 +1,104 @@
+// Gologcollect receives LogMessages sent by golog's UDP and TCP LogOuters and
+// writes them to local files or stdout. For example, to collect messages sent
+// to port 5140 into a rotating file in JSON format:
+//	gologcollect --udp=:5140 --tcp=:5140 --rotate=fleet.log \
+//		--rotate_max_size=104857600 --golog.logformat=json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/awreece/golog"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+var (
+	udpAddr = flag.String("udp", "", "Address to receive UDP datagrams on")
+	tcpAddr = flag.String("tcp", "", "Address to accept TCP connections on")
+	framing = flag.String("framing", "newline",
+		"Framing of TCP streams - one of newline or length")
+	stdout = flag.Bool("stdout", false, "Write received messages to stdout")
+
+	rotateFile = flag.String("rotate", "",
+		"Write received messages to this file, rotating it")
+	rotateMaxSize = flag.Int64("rotate_max_size", 0,
+		"Rotate the file once it reaches this many bytes")
+	rotateMaxBackups = flag.Int("rotate_max_backups", 0,
+		"Keep at most this many rotated files")
+	rotateCompress = flag.Bool("rotate_compress", false,
+		"Gzip rotated files")
+)
+
+// Received messages are written to every file provided via --logfile, as well
+// as to stdout and the rotating file, in the format selected via
+// --golog.logformat.
+var outers golog.MultiLogOuterFlag = golog.NewMultiLogOuter()
+
+func init() {
+	flag.Var(outers, "logfile", "Write received messages to the file - "+
+		"can be provided multiple times")
+}
+
+func fatal(msg ...interface{}) {
+	fmt.Fprintln(os.Stderr, msg...)
+	os.Exit(1)
+}
+
+func main() {
+	flag.Parse()
+
+	if *stdout {
+		outers.AddLogOuter("stdout", golog.NewWriterLogOuterWithFormatter(
+			os.Stdout, golog.LogFormatFlag()))
+	}
+	if *rotateFile != "" {
+		outer, err := golog.NewRotatingFileLogOuter(*rotateFile,
+			golog.RotateOptions{
+				MaxSize:    *rotateMaxSize,
+				MaxBackups: *rotateMaxBackups,
+				Compress:   *rotateCompress,
+				Formatter:  golog.LogFormatFlag(),
+			})
+		if err != nil {
+			fatal("Error opening", *rotateFile, ":", err)
+		}
+		outers.AddLogOuter(*rotateFile, outer)
+	}
+
+	var streamFraming golog.Framing
+	switch *framing {
+	case "newline":
+		streamFraming = golog.FramingNewline
+	case "length":
+		streamFraming = golog.FramingLengthPrefix
+	default:
+		fatal("Unknown framing", *framing)
+	}
+
+	if *udpAddr == "" && *tcpAddr == "" {
+		fatal("At least one of --udp or --tcp must be provided")
+	}
+
+	collector := golog.NewCollector(outers, streamFraming)
+	if *udpAddr != "" {
+		if _, err := collector.ListenUDP(*udpAddr); err != nil {
+			fatal("Error listening on", *udpAddr, ":", err)
+		}
+	}
+	if *tcpAddr != "" {
+		if _, err := collector.ListenTCP(*tcpAddr); err != nil {
+			fatal("Error listening on", *tcpAddr, ":", err)
+		}
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	<-signals
+
+	collector.Close()
+	outers.(golog.Flusher).Flush()
+	outers.(io.Closer).Close()
+}
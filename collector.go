@@ -0,0 +1,221 @@
+package golog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// The largest frame a Collector will accept on a stream, to bound the memory
+// used by a misbehaving sender.
+const maxCollectorFrameSize = 16 << 20
+
+// A Collector receives LogMessages sent by NewUDPLogOuter or NewTCPLogOuter
+// and outputs them to a local LogOuter. For example, to write every received
+// LogMessage to a file:
+//	outer, _ := golog.NewFileLogOuter("fleet.log")
+//	collector := golog.NewCollector(outer, golog.FramingNewline)
+//	collector.ListenUDP(":5140")
+//	collector.ListenTCP(":5140")
+type Collector struct {
+	// Accessed atomically, so first in the struct to ensure alignment.
+	received uint64
+	errors   uint64
+
+	outer   LogOuter
+	framing Framing
+
+	lock    sync.Mutex
+	closers map[io.Closer]bool
+	closed  bool
+	// Counts the tracked closers, so that Close can wait for them to be
+	// served. Only incremented with the lock held and before closed is
+	// set.
+	serving sync.WaitGroup
+}
+
+// Returns a Collector that outputs to the LogOuter and expects the framing on
+// TCP connections.
+func NewCollector(outer LogOuter, framing Framing) *Collector {
+	return &Collector{
+		outer:   outer,
+		framing: framing,
+		closers: make(map[io.Closer]bool),
+	}
+}
+
+// Tracks the closer so it is closed by Close(), which waits until it is
+// untracked. Returns false if the Collector is already closed, in which case
+// the closer is closed.
+func (c *Collector) track(closer io.Closer) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		closer.Close()
+		return false
+	}
+	c.closers[closer] = true
+	c.serving.Add(1)
+	return true
+}
+
+// Stops tracking a closer tracked by track().
+func (c *Collector) untrack(closer io.Closer) {
+	c.lock.Lock()
+	delete(c.closers, closer)
+	c.lock.Unlock()
+
+	c.serving.Done()
+}
+
+// Decodes and outputs a single JSON encoded LogMessage.
+func (c *Collector) dispatch(data []byte) {
+	var m LogMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		atomic.AddUint64(&c.errors, 1)
+		return
+	}
+	atomic.AddUint64(&c.received, 1)
+	c.outer.Output(&m)
+}
+
+// Listens for datagrams on the UDP address and serves them in the background.
+// Returns the address listened on, or an error if the address cannot be
+// listened on.
+func (c *Collector) ListenUDP(laddr string) (net.Addr, error) {
+	conn, err := net.ListenPacket("udp", laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	go c.ServeUDP(conn)
+	return conn.LocalAddr(), nil
+}
+
+// Outputs the LogMessage in each datagram received on the connection until it
+// is closed. Returns an error without serving if the Collector is closed.
+func (c *Collector) ServeUDP(conn net.PacketConn) error {
+	if !c.track(conn) {
+		return fmt.Errorf("collector closed")
+	}
+	defer c.untrack(conn)
+	defer conn.Close()
+
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		c.dispatch(buf[:n])
+	}
+
+	panic("Code never reaches here, this mollifies the compiler.")
+}
+
+// Listens for connections on the TCP address and serves them in the
+// background. Returns the address listened on, or an error if the address
+// cannot be listened on.
+func (c *Collector) ListenTCP(laddr string) (net.Addr, error) {
+	listener, err := net.Listen("tcp", laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	go c.ServeTCP(listener)
+	return listener.Addr(), nil
+}
+
+// Accepts connections on the listener and outputs the LogMessages received on
+// each until the listener is closed. Returns an error without serving if the
+// Collector is closed.
+func (c *Collector) ServeTCP(listener net.Listener) error {
+	if !c.track(listener) {
+		return fmt.Errorf("collector closed")
+	}
+	defer c.untrack(listener)
+	defer listener.Close()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		if !c.track(conn) {
+			return fmt.Errorf("collector closed")
+		}
+
+		go func() {
+			defer c.untrack(conn)
+			defer conn.Close()
+			c.serveStream(conn)
+		}()
+	}
+
+	panic("Code never reaches here, this mollifies the compiler.")
+}
+
+// Outputs the LogMessages in each frame read from the stream until it is
+// closed or a frame cannot be read.
+func (c *Collector) serveStream(r io.Reader) {
+	reader := bufio.NewReader(r)
+
+	for {
+		var data []byte
+		var err error
+
+		switch c.framing {
+		case FramingLengthPrefix:
+			var size uint32
+			if err = binary.Read(reader, binary.BigEndian, &size); err != nil {
+				return
+			}
+			if size > maxCollectorFrameSize {
+				// We can't find the next frame, give up.
+				atomic.AddUint64(&c.errors, 1)
+				return
+			}
+			data = make([]byte, size)
+			if _, err = io.ReadFull(reader, data); err != nil {
+				return
+			}
+		default:
+			if data, err = reader.ReadBytes('\n'); err != nil {
+				return
+			}
+		}
+
+		c.dispatch(data)
+	}
+}
+
+// Stops listening, closes every connection, and waits for them to finish,
+// including calls to ServeUDP and ServeTCP made by the caller. Does not close
+// the LogOuter.
+func (c *Collector) Close() error {
+	c.lock.Lock()
+	c.closed = true
+	for closer, _ := range c.closers {
+		closer.Close()
+	}
+	c.lock.Unlock()
+
+	c.serving.Wait()
+	return nil
+}
+
+// Returns the number of LogMessages received and output.
+func (c *Collector) Received() uint64 {
+	return atomic.LoadUint64(&c.received)
+}
+
+// Returns the number of LogMessages that could not be decoded.
+func (c *Collector) Errors() uint64 {
+	return atomic.LoadUint64(&c.errors)
+}
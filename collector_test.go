@@ -0,0 +1,90 @@
+package golog
+
+import (
+	"net"
+	"runtime"
+	"testing"
+)
+
+func TestCollector(t *testing.T) {
+	received := make(chanLogOuter, 10)
+	collector := NewCollector(received, FramingLengthPrefix)
+	defer collector.Close()
+
+	udpAddr, err := collector.ListenUDP("127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Error listening on UDP:", err)
+	}
+	tcpAddr, err := collector.ListenTCP("127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Error listening on TCP:", err)
+	}
+
+	udpOuter, err := NewUDPLogOuter(udpAddr.String())
+	if err != nil {
+		t.Fatal("Error creating UDP LogOuter:", err)
+	}
+	defer closeLogOuter(udpOuter)
+
+	tcpOuter, err := NewTCPLogOuter(tcpAddr.String(),
+		TCPOptions{Framing: FramingLengthPrefix})
+	if err != nil {
+		t.Fatal("Error creating TCP LogOuter:", err)
+	}
+	defer tcpOuter.Close()
+
+	udpOuter.Output(&LogMessage{
+		Level:    WARNING,
+		Message:  "udp",
+		Metadata: map[string]string{"file": "foo.go"},
+	})
+	if m := receive(t, received); m.Message != "udp" || m.Level != WARNING ||
+		m.Metadata["file"] != "foo.go" {
+		t.Errorf("Expected udp message, got %v", m)
+	}
+
+	tcpOuter.Output(&LogMessage{Level: ERROR, Message: "tcp"})
+	if m := receive(t, received); m.Message != "tcp" || m.Level != ERROR {
+		t.Errorf("Expected tcp message, got %v", m)
+	}
+
+	if collector.Received() != 2 || collector.Errors() != 0 {
+		t.Errorf("Expected 2 received and 0 errors, got %d and %d",
+			collector.Received(), collector.Errors())
+	}
+}
+
+func TestCollectorCloseWaitsForServe(t *testing.T) {
+	collector := NewCollector(make(chanLogOuter, 1), FramingNewline)
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Error listening on UDP:", err)
+	}
+
+	served := make(chan error, 1)
+	go func() { served <- collector.ServeUDP(conn) }()
+	for tracked := false; !tracked; runtime.Gosched() {
+		collector.lock.Lock()
+		tracked = collector.closers[conn]
+		collector.lock.Unlock()
+	}
+
+	collector.Close()
+	select {
+	case <-served:
+	default:
+		t.Error("Close returned before ServeUDP")
+	}
+
+	// Serving after Close fails, and closes the connection.
+	conn, err = net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Error listening on UDP:", err)
+	}
+	if err := collector.ServeUDP(conn); err == nil {
+		t.Error("Served after Close")
+	}
+	if _, _, err := conn.ReadFrom(make([]byte, 1)); err == nil {
+		t.Error("Connection not closed")
+	}
+}
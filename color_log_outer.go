@@ -0,0 +1,93 @@
+package golog
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+const (
+	ansiReset    = "\x1b[0m"
+	ansiLocation = "\x1b[36m"
+)
+
+// The ANSI escape sequence used to color messages at each level. Messages at
+// levels not present are not colored.
+var levelColors = map[int]string{
+	TRACE:   "\x1b[2m",
+	DEBUG:   "\x1b[2m",
+	INFO:    "\x1b[2m",
+	WARNING: "\x1b[33m",
+	ERROR:   "\x1b[31m",
+	FATAL:   "\x1b[1;31m",
+}
+
+type colorFormatter struct{}
+
+// Returns a Formatter that renders messages in the same format as the
+// DefaultFormatter, but colored by level with ANSI escape sequences and with
+// the location highlighted.
+func NewColorFormatter() Formatter {
+	return colorFormatter{}
+}
+
+func (f colorFormatter) Format(m *LogMessage) []byte {
+	var buf bytes.Buffer
+	color := levelColors[m.Level]
+
+	buf.WriteString(color)
+	buf.WriteString(levelName(m.Level))
+	buf.WriteString(m.Nanoseconds.Format(" " + defaultTemplateTimeLayout))
+	buf.WriteString(" ")
+	buf.WriteString(ansiReset)
+	buf.WriteString(ansiLocation)
+	buf.WriteString(m.Metadata["file"])
+	buf.WriteString(":")
+	buf.WriteString(m.Metadata["line"])
+	buf.WriteString(ansiReset)
+	buf.WriteString(color)
+	buf.WriteString("] ")
+	buf.WriteString(m.Message)
+	renderFields(&buf, m, locationKeys)
+	buf.WriteString(ansiReset)
+	return buf.Bytes()
+}
+
+// Returns true if the writer is a terminal that colored output should be
+// written to. Respects the NO_COLOR convention (http://no-color.org).
+func useColor(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	if file, ok := w.(*os.File); ok {
+		if info, err := file.Stat(); err == nil {
+			return info.Mode()&os.ModeCharDevice != 0
+		}
+	}
+	return false
+}
+
+// Returns a LogOuter wrapping the io.Writer that colors messages by level if
+// the io.Writer is a terminal and the NO_COLOR environment variable is not
+// set. Otherwise, messages are rendered with the DefaultFormatter. For
+// example,
+//	golog.AddLogOuter("stderr", golog.NewColorLogOuter(os.Stderr))
+func NewColorLogOuter(w io.Writer) LogOuter {
+	if useColor(w) {
+		return NewWriterLogOuterWithFormatter(w, NewColorFormatter())
+	}
+	return NewWriterLogOuter(w)
+}
+
+// A Formatter for terminals that colors messages unless the wrapped flag has
+// been set.
+type terminalFormatter struct {
+	format FormatterFlag
+}
+
+func (f *terminalFormatter) Format(m *LogMessage) []byte {
+	if f.format.String() == "" {
+		return colorFormatter{}.Format(m)
+	}
+	return f.format.Format(m)
+}
@@ -0,0 +1,133 @@
+package golog
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+type contextKey int
+
+const (
+	loggerContextKey contextKey = iota
+	fieldsContextKey
+)
+
+// A ContextExtractor returns the Fields to add to the Metadata of a LogMessage
+// logged with the context. Extractors are only called if the message will be
+// logged.
+type ContextExtractor func(ctx context.Context) []Field
+
+type registeredExtractor struct {
+	name      string
+	extractor ContextExtractor
+}
+
+var extractorLock sync.RWMutex
+
+// Sorted by name, so that the result is deterministic if extractors add the
+// same key.
+var contextExtractors []*registeredExtractor
+
+// Registers the extractor under the name, replacing any extractor previously
+// registered with the name. Extractors are called in order of their names, so
+// if two add the same key, the value added by the later name is used. For
+// example, to add the trace ID stored by a tracing package to every LogMessage
+// logged with a context:
+//	golog.RegisterContextExtractor("trace", func(ctx context.Context) []golog.Field {
+//		if id, ok := trace.IDFromContext(ctx); ok {
+//			return []golog.Field{golog.String("trace_id", id)}
+//		}
+//		return nil
+//	})
+func RegisterContextExtractor(name string, extractor ContextExtractor) {
+	extractorLock.Lock()
+	defer extractorLock.Unlock()
+
+	removeContextExtractor(name)
+	i := sort.Search(len(contextExtractors), func(i int) bool {
+		return contextExtractors[i].name >= name
+	})
+	contextExtractors = append(contextExtractors, nil)
+	copy(contextExtractors[i+1:], contextExtractors[i:])
+	contextExtractors[i] = &registeredExtractor{name, extractor}
+}
+
+// Removes the extractor registered under the name.
+func UnregisterContextExtractor(name string) {
+	extractorLock.Lock()
+	defer extractorLock.Unlock()
+
+	removeContextExtractor(name)
+}
+
+// Must be called with the lock held.
+func removeContextExtractor(name string) {
+	for i, e := range contextExtractors {
+		if e.name == name {
+			contextExtractors = append(contextExtractors[:i],
+				contextExtractors[i+1:]...)
+			return
+		}
+	}
+}
+
+// Returns a copy of the context that carries the PackageLogger, which is used
+// by the package level Ctx functions.
+func NewContext(ctx context.Context, logger *PackageLogger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, logger)
+}
+
+// Returns the PackageLogger carried by the context, or Global if there is
+// none.
+func FromContext(ctx context.Context) *PackageLogger {
+	if logger, ok := ctx.Value(loggerContextKey).(*PackageLogger); ok {
+		return logger
+	}
+	return Global
+}
+
+// Returns a copy of the context that carries the fields, in addition to any
+// already carried. keysAndValues is as in Logw. Every LogMessage logged via a
+// Ctx method with the context has the fields added to its Metadata.
+func ContextWith(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	old, _ := ctx.Value(fieldsContextKey).([]Field)
+	fields := make([]Field, 0, len(old)+len(keysAndValues))
+	fields = append(fields, old...)
+	fields = append(fields, makeFields(keysAndValues)...)
+	return context.WithValue(ctx, fieldsContextKey, fields)
+}
+
+// Returns the fields carried by the context followed by a Field standing for
+// the fields returned by every registered extractor, which are only extracted
+// if the message will be logged.
+func contextFields(ctx context.Context) []Field {
+	fields, _ := ctx.Value(fieldsContextKey).([]Field)
+
+	extractorLock.RLock()
+	none := len(contextExtractors) == 0
+	extractorLock.RUnlock()
+	if none {
+		return fields
+	}
+
+	// Don't modify the slice carried by the context.
+	all := make([]Field, len(fields), len(fields)+1)
+	copy(all, fields)
+	return append(all, Field{expand: func() []Field {
+		return extractedFields(ctx)
+	}})
+}
+
+// Returns the fields returned by every registered extractor, in order of
+// their names.
+func extractedFields(ctx context.Context) []Field {
+	extractorLock.RLock()
+	defer extractorLock.RUnlock()
+
+	var fields []Field
+	for _, e := range contextExtractors {
+		fields = append(fields, e.extractor(ctx)...)
+	}
+	return fields
+}
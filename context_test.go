@@ -0,0 +1,60 @@
+package golog
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+	logger, received := newChanLogger(1, INFO, NoLocation)
+
+	type tenantKey struct{}
+	RegisterContextExtractor("tenant", func(ctx context.Context) []Field {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return []Field{String("tenant", tenant)}
+		}
+		return nil
+	})
+	defer UnregisterContextExtractor("tenant")
+
+	ctx := NewContext(context.Background(), logger.With("bound", 1))
+	ctx = ContextWith(ctx, "request", "r1")
+	ctx = context.WithValue(ctx, tenantKey{}, "t1")
+
+	InfoCtx(ctx, "hello")
+
+	m := receive(t, received)
+	expected := map[string]string{"bound": "1", "request": "r1",
+		"tenant": "t1"}
+	for key, value := range expected {
+		if m.Metadata[key] != value {
+			t.Errorf("Expected %s=%s, got %v", key, value, m.Metadata)
+		}
+	}
+}
+
+func TestContextExtractorOrder(t *testing.T) {
+	logger, received := newChanLogger(1, INFO, NoLocation)
+
+	called := false
+	for _, name := range []string{"b", "c", "a"} {
+		value := name
+		RegisterContextExtractor(name, func(ctx context.Context) []Field {
+			called = true
+			return []Field{String("key", value)}
+		})
+		defer UnregisterContextExtractor(name)
+	}
+
+	logger.DebugCtx(context.Background(), "dropped")
+	if called {
+		t.Error("Extractor called even though no output produced")
+	}
+
+	for i := 0; i < 10; i++ {
+		logger.InfoCtx(context.Background(), "hello")
+		if m := receive(t, received); m.Metadata["key"] != "c" {
+			t.Errorf("Expected key=c, got %v", m.Metadata)
+		}
+	}
+}
@@ -14,8 +14,10 @@ example:
 	import "github.com/awreece/golog"
 
 	func Foo() {
+		golog.Debugf("Entering Foo()")
 		golog.Info("Hello, world")
 		golog.Warningf("Error %d", 4)
+		golog.Infow("Request served", "user", id, golog.Int("status", 200))
 		golog.Errorc(func() { return verySlowStringFunction() })
 		golog.Fatal("Error opening file:", err)
 	}
@@ -23,6 +25,17 @@ example:
 The Global PackageLogger outputs to default files set by flags. For example,
 to log to stderr and to temp.log, invoke the binary with the additional
 flags --golog.logfile=/dev/stderr --golog.logfile=temp.log.
+The minimum level can be overridden for individual files or packages with
+--golog.vmodule, for example --golog.vmodule=server=debug,github.com/me/db*=error.
+Fine grained INFO logging is enabled by verbosity with V, as in
+golog.V(2).Infof(...), which logs only if --golog.v is at least 2 or an entry
+such as server=v2 in --golog.vmodule matches the caller. The flag
+--golog.log_backtrace_at=server.go:123 appends the stack trace of the
+goroutine to every message logged at that line.
+The format of the logfiles can be selected with --golog.logformat, which
+accepts default, json, logfmt, glog, color, or a template (see
+NewTemplateFormatter). If no format is selected, logfiles that are terminals
+are colored by level unless the NO_COLOR environment variable is set.
 
 This package also makes it easy to log to a testing harness in addition to
 files. To do this, invoke StartTestLogging(t) at the start of every test
@@ -65,6 +78,10 @@ each logical component. The important types are:
 
 -	A LogOuter controls the formatted output of a LogMessage.
 
+-	A Formatter renders a LogMessage into bytes, allowing the layout of
+the output of a LogOuter to be changed independently of where it is
+written.
+
 -	A MultiLogOuter multiplexes an outputted message to a set of keyed
 LogOuters. The associated MultiLogOuterFlag automatically adds 
 logfiles to the associated set of LogOuters.
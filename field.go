@@ -0,0 +1,118 @@
+package golog
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// A Field is a key and a lazily rendered value to add to the Metadata of a
+// LogMessage. The value is only rendered if the message will be logged.
+type Field struct {
+	Key    string
+	render func() string
+	// If not nil, the Field stands for the Fields returned by expand,
+	// which is only called if the message will be logged.
+	expand func() []Field
+}
+
+// Returns the rendered value of the Field.
+func (f Field) Value() string {
+	if f.render == nil {
+		return ""
+	}
+	return f.render()
+}
+
+// Returns a Field with the string value.
+func String(key, val string) Field {
+	return Lazy(key, func() string { return val })
+}
+
+// Returns a Field with the int value.
+func Int(key string, val int) Field {
+	return Lazy(key, func() string { return strconv.Itoa(val) })
+}
+
+// Returns a Field with the int64 value.
+func Int64(key string, val int64) Field {
+	return Lazy(key, func() string { return strconv.FormatInt(val, 10) })
+}
+
+// Returns a Field with the float64 value.
+func Float64(key string, val float64) Field {
+	return Lazy(key, func() string {
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	})
+}
+
+// Returns a Field with the bool value.
+func Bool(key string, val bool) Field {
+	return Lazy(key, func() string { return strconv.FormatBool(val) })
+}
+
+// Returns a Field with the time.Duration value.
+func Duration(key string, val time.Duration) Field {
+	return Lazy(key, func() string { return val.String() })
+}
+
+// Returns a Field with the key "error" and the error as the value.
+func Err(err error) Field {
+	return Lazy("error", func() string {
+		if err == nil {
+			return "<nil>"
+		}
+		return err.Error()
+	})
+}
+
+// Returns a Field with the value formatted as if via a call to fmt.Sprint.
+func Any(key string, val interface{}) Field {
+	return Lazy(key, func() string { return fmt.Sprint(val) })
+}
+
+// Returns a Field whose value is the result of the closure. Only evaluates the
+// closure if the message will be logged.
+func Lazy(key string, closure func() string) Field {
+	return Field{Key: key, render: closure}
+}
+
+// The key used for a value without a key in a list of keys and values.
+const missingKey = "!BADKEY"
+
+// Converts a list of alternating keys and values into Fields. Fields in the
+// list are used as is. A key that is not a string is formatted as if via a
+// call to fmt.Sprint. A value without a key is given the key "!BADKEY".
+func makeFields(keysAndValues []interface{}) []Field {
+	fields := make([]Field, 0, len(keysAndValues)/2)
+
+	for i := 0; i < len(keysAndValues); i++ {
+		if field, ok := keysAndValues[i].(Field); ok {
+			fields = append(fields, field)
+			continue
+		}
+		if i == len(keysAndValues)-1 {
+			fields = append(fields, Any(missingKey, keysAndValues[i]))
+			break
+		}
+
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		fields = append(fields, Any(key, keysAndValues[i+1]))
+		i++
+	}
+	return fields
+}
+
+// Adds the rendered fields to the metadata, overwriting any existing keys.
+func addFields(metadata map[string]string, fields []Field) {
+	for _, field := range fields {
+		if field.expand != nil {
+			addFields(metadata, field.expand())
+			continue
+		}
+		metadata[field.Key] = field.Value()
+	}
+}
@@ -0,0 +1,36 @@
+package golog
+
+import (
+	"testing"
+)
+
+func TestInfow(t *testing.T) {
+	logger, received := newChanLogger(1, INFO, NoLocation)
+	logger.Infow("hello", "user", 42, Bool("ok", true), "dangling")
+
+	m := receive(t, received)
+	expected := map[string]string{"user": "42", "ok": "true",
+		"!BADKEY": "dangling"}
+	if len(m.Metadata) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, m.Metadata)
+	}
+	for key, value := range expected {
+		if m.Metadata[key] != value {
+			t.Errorf("Expected %s=%s, got %v", key, value, m.Metadata)
+		}
+	}
+}
+
+func TestFieldsNotRenderedBelowLevel(t *testing.T) {
+	logger := NewPackageLogger(NewMultiLogOuter(), ERROR, nil, NoLocation)
+
+	var called bool = false
+	logger.Infow("hello", Lazy("slow", func() string {
+		called = true
+		return ""
+	}))
+
+	if called {
+		t.Error("Field rendered even though no output produced")
+	}
+}
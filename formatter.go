@@ -0,0 +1,138 @@
+package golog
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// A Formatter renders a LogMessage into the bytes output by a LogOuter. The
+// rendered bytes should not include a trailing newline; LogOuters that need
+// one will insert it themselves. Format must be safe to call from multiple
+// threads.
+type Formatter interface {
+	Format(m *LogMessage) []byte
+}
+
+// A FormatterFunc is an adapter to allow the use of ordinary functions as
+// Formatters.
+type FormatterFunc func(m *LogMessage) []byte
+
+// Implements Formatter.Format().
+func (f FormatterFunc) Format(m *LogMessage) []byte {
+	return f(m)
+}
+
+// The template of the DefaultFormatter (see NewTemplateFormatter).
+const defaultTemplate = "{level} {time} {file}:{line}] {message}{fields}"
+
+// The Formatter used by LogOuters that are not given one explicitly. Format is:
+// "{level} {time} {file}:{line}] {message} {key}={value}..."
+// where the remaining Metadata, such as the package and function, follows the
+// message in sorted order. To render other Metadata before the message, use a
+// template such as
+//	"{level} {time} {pid} g{goroutine} #{seq} {file}:{line}] {message}{fields}"
+var DefaultFormatter Formatter = mustTemplateFormatter(defaultTemplate)
+
+// Returns the Formatter for the template, which must parse.
+func mustTemplateFormatter(template string) Formatter {
+	formatter, err := NewTemplateFormatter(template)
+	if err != nil {
+		panic(err)
+	}
+	return formatter
+}
+
+// A Formatter that can be used as a flag to select the format of a LogOuter.
+// The flag accepts the name of a registered Formatter ("default", "json",
+// "logfmt", "glog", or "color"), otherwise the flag is parsed as a template (see
+// NewTemplateFormatter). For example,
+//	var myFormat FormatterFlag = NewFormatterFlag(DefaultFormatter)
+//	
+//	func init() {
+//		flag.Var(myFormat, "mypack.logformat", "Format of log output")
+//	}
+type FormatterFlag interface {
+	Formatter
+	flag.Value
+}
+
+var namedFormatters = map[string]func() Formatter{
+	"default": func() Formatter { return DefaultFormatter },
+	"json":    NewJSONFormatter,
+	"logfmt":  NewLogfmtFormatter,
+	"glog":    NewGlogFormatter,
+	"color":   NewColorFormatter,
+}
+
+type formatterFlagImpl struct {
+	// Guards formatter and value, since the flag may be set while other
+	// goroutines are formatting.
+	lock      sync.RWMutex
+	formatter Formatter
+	value     string
+}
+
+// Returns a FormatterFlag that delegates to the provided Formatter until the
+// flag is set.
+func NewFormatterFlag(formatter Formatter) FormatterFlag {
+	return &formatterFlagImpl{formatter: formatter}
+}
+
+// Implements Formatter.Format().
+func (f *formatterFlagImpl) Format(m *LogMessage) []byte {
+	f.lock.RLock()
+	formatter := f.formatter
+	f.lock.RUnlock()
+
+	return formatter.Format(m)
+}
+
+func (f *formatterFlagImpl) Set(val string) bool {
+	var formatter Formatter
+	if newFormatter, ok := namedFormatters[val]; ok {
+		formatter = newFormatter()
+	} else if !strings.Contains(val, "{") {
+		// Almost certainly a misspelled name rather than a template.
+		fmt.Println("Error setting flag: unknown format", val)
+		return false
+	} else if templateFormatter, err := NewTemplateFormatter(val); err == nil {
+		formatter = templateFormatter
+	} else {
+		fmt.Println("Error setting flag: ", err)
+		return false
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.formatter = formatter
+	f.value = val
+	return true
+}
+
+func (f *formatterFlagImpl) String() string {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.value
+}
+
+// The Formatter used by files added via the golog.logfile flag.
+var defaultLogFormat FormatterFlag = NewFormatterFlag(DefaultFormatter)
+
+func init() {
+	flag.Var(defaultLogFormat, "golog.logformat",
+		"Format of messages logged to files - one of default, json, "+
+			"logfmt, glog, color, or a template such as "+
+			"\"{level} {time} {file}:{line}] {message}\"")
+}
+
+// Returns the FormatterFlag set by the golog.logformat flag, so that LogOuters
+// created by hand can use the same format as the logfiles. The format is
+// looked up every time a LogMessage is formatted, so it may be called before
+// the flags are parsed.
+func LogFormatFlag() FormatterFlag {
+	return defaultLogFormat
+}
@@ -0,0 +1,245 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWriterLogOuterWithFormatter(t *testing.T) {
+	var buf bytes.Buffer
+
+	formatter := FormatterFunc(func(m *LogMessage) []byte {
+		return []byte(m.Message)
+	})
+	outer := NewWriterLogOuterWithFormatter(&buf, formatter)
+	outer.Output(&LogMessage{Message: "hello"})
+
+	if buf.String() != "hello\n" {
+		t.Errorf("Expected %q, got %q", "hello\n", buf.String())
+	}
+}
+
+func TestDefaultFormatter(t *testing.T) {
+	message := &LogMessage{
+		Level:       ERROR,
+		Nanoseconds: time.Date(2011, 12, 1, 15, 4, 5, 6000, time.UTC),
+		Message:     "hello",
+		Metadata:    map[string]string{"file": "foo.go", "line": "12"},
+	}
+
+	expected := "ERROR 15:04:05.000006 foo.go:12] hello"
+	if actual := string(DefaultFormatter.Format(message)); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	message.Metadata["pid"] = "42"
+	message.Metadata["goroutine"] = "7"
+	message.Metadata["seq"] = "3"
+	expected = "ERROR 15:04:05.000006 foo.go:12] hello goroutine=7 pid=42 seq=3"
+	if actual := string(DefaultFormatter.Format(message)); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	header, _ := NewTemplateFormatter(
+		"{level} {time} {pid} g{goroutine} #{seq} {file}:{line}] {message}{fields}")
+	expected = "ERROR 15:04:05.000006 42 g7 #3 foo.go:12] hello"
+	if actual := string(header.Format(message)); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+	delete(message.Metadata, "pid")
+	delete(message.Metadata, "goroutine")
+	delete(message.Metadata, "seq")
+
+	message.Metadata["user"] = "a b"
+	expected = "ERROR 15:04:05.000006 foo.go:12] hello user=\"a b\""
+	if actual := string(DefaultFormatter.Format(message)); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	message.Stack = []string{"main.f /src/foo.go:12", "main.main /src/main.go:3"}
+	expected = "ERROR 15:04:05.000006 foo.go:12] hello user=\"a b\"" +
+		"\n\tmain.f /src/foo.go:12\n\tmain.main /src/main.go:3"
+	if actual := string(DefaultFormatter.Format(message)); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	// A field named stack is just a field.
+	message.Stack = nil
+	message.Metadata["stack"] = "a\nb"
+	expected = "ERROR 15:04:05.000006 foo.go:12] hello stack=\"a\\nb\" user=\"a b\""
+	if actual := string(DefaultFormatter.Format(message)); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestJSONFormatter(t *testing.T) {
+	message := &LogMessage{
+		Level:       WARNING,
+		Nanoseconds: time.Date(2011, 12, 1, 15, 4, 5, 6000, time.UTC),
+		Message:     "say \"hi\"",
+		Metadata: map[string]string{
+			"line":    "12",
+			"file":    "foo.go",
+			"message": "dropped",
+		},
+	}
+
+	expected := `{"level":"WARNING","levelnum":100,` +
+		`"time":"2011-12-01T15:04:05.000006Z","message":"say \"hi\"",` +
+		`"file":"foo.go","line":"12"}`
+	if actual := string(NewJSONFormatter().Format(message)); actual != expected {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+
+	message.Metadata["seq"] = "3"
+	message.Metadata["stack"] = "a"
+	expected = `{"level":"WARNING","levelnum":100,` +
+		`"time":"2011-12-01T15:04:05.000006Z","message":"say \"hi\"",` +
+		`"file":"foo.go","line":"12","seq":3,"stack":"a"}`
+	if actual := string(NewJSONFormatter().Format(message)); actual != expected {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+
+	message.Stack = []string{"main.f /src/foo.go:12", "main.main /src/main.go:3"}
+	expected = `{"level":"WARNING","levelnum":100,` +
+		`"time":"2011-12-01T15:04:05.000006Z","message":"say \"hi\"",` +
+		`"file":"foo.go","line":"12","seq":3,` +
+		`"stack":["main.f /src/foo.go:12","main.main /src/main.go:3"]}`
+	if actual := string(NewJSONFormatter().Format(message)); actual != expected {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestLogfmtFormatter(t *testing.T) {
+	message := &LogMessage{
+		Level:       ERROR,
+		Nanoseconds: time.Date(2011, 12, 1, 15, 4, 5, 6000, time.UTC),
+		Message:     "say \"hi\"",
+		Metadata: map[string]string{
+			"line":     "12",
+			"file":     "foo.go",
+			"my key":   "a=b",
+			"hostname": "",
+		},
+	}
+
+	expected := `level=ERROR ts=2011-12-01T15:04:05.000006Z file=foo.go ` +
+		`hostname="" line=12 my_key="a=b" msg="say \"hi\""`
+	if actual := string(NewLogfmtFormatter().Format(message)); actual != expected {
+		t.Errorf("Expected %s, got %s", expected, actual)
+	}
+}
+
+func TestGlogFormatter(t *testing.T) {
+	message := &LogMessage{
+		Level:       ERROR,
+		Nanoseconds: time.Date(2011, 10, 18, 15, 4, 5, 6000, time.UTC),
+		Message:     "hello",
+		Metadata: map[string]string{
+			"file": "foo.go",
+			"line": "12",
+			"pid":  "12345",
+		},
+	}
+
+	expected := "E1018 15:04:05.000006   12345 foo.go:12] hello"
+	if actual := string(NewGlogFormatter().Format(message)); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+
+	delete(message.Metadata, "pid")
+	expected = fmt.Sprintf("E1018 15:04:05.000006 %7d foo.go:12] hello", os.Getpid())
+	if actual := string(NewGlogFormatter().Format(message)); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestTemplateFormatter(t *testing.T) {
+	message := &LogMessage{
+		Level:       ERROR,
+		Nanoseconds: time.Date(2011, 10, 18, 15, 4, 5, 6000, time.UTC),
+		Message:     "hello",
+		Metadata:    map[string]string{"file": "foo.go", "line": "12"},
+	}
+
+	formatter, err := NewTemplateFormatter(
+		"{{{level}}} {time:2006-01-02} {file}:{line} {hostname}] {message}")
+	if err != nil {
+		t.Fatal("Error parsing template:", err)
+	}
+
+	expected := "{ERROR} 2011-10-18 foo.go:12 ] hello"
+	if actual := string(formatter.Format(message)); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestTemplateFormatterErrors(t *testing.T) {
+	for _, template := range []string{"{level", "level}", "{}", "{file:x}"} {
+		if _, err := NewTemplateFormatter(template); err == nil {
+			t.Errorf("Expected error parsing template %q", template)
+		}
+	}
+}
+
+func TestColorFormatter(t *testing.T) {
+	message := &LogMessage{
+		Level:       ERROR,
+		Nanoseconds: time.Date(2011, 10, 18, 15, 4, 5, 6000, time.UTC),
+		Message:     "hello",
+		Metadata:    map[string]string{"file": "foo.go", "line": "12"},
+	}
+
+	expected := "\x1b[31mERROR 15:04:05.000006 \x1b[0m\x1b[36mfoo.go:12\x1b[0m" +
+		"\x1b[31m] hello\x1b[0m"
+	if actual := string(NewColorFormatter().Format(message)); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestColorLogOuterNotTerminal(t *testing.T) {
+	var buf bytes.Buffer
+
+	NewColorLogOuter(&buf).Output(&LogMessage{Level: ERROR, Message: "hello"})
+	if bytes.Contains(buf.Bytes(), []byte("\x1b[")) {
+		t.Errorf("Colored output written to non-terminal: %q", buf.String())
+	}
+}
+
+func TestLogFormatFlag(t *testing.T) {
+	formatter := LogFormatFlag()
+	// Restore the unset flag afterwards.
+	impl := defaultLogFormat.(*formatterFlagImpl)
+	saved, value := impl.formatter, impl.value
+	defer func() { impl.formatter, impl.value = saved, value }()
+
+	if !formatter.Set("{message}!") {
+		t.Fatal("Error setting format")
+	}
+	if actual := string(formatter.Format(&LogMessage{Message: "hi"})); actual != "hi!" {
+		t.Errorf("Expected %q, got %q", "hi!", actual)
+	}
+}
+
+func TestFormatterFlagSetWhileFormatting(t *testing.T) {
+	formatter := NewFormatterFlag(DefaultFormatter)
+	done := make(chan bool)
+
+	go func() {
+		for i := 0; i < 100; i++ {
+			formatter.Format(&LogMessage{Message: "hi"})
+		}
+		done <- true
+	}()
+	for i := 0; i < 100; i++ {
+		formatter.Set("json")
+		formatter.Set("{message}")
+	}
+	<-done
+
+	if actual := string(formatter.Format(&LogMessage{Message: "hi"})); actual != "hi" {
+		t.Errorf("Expected %q, got %q", "hi", actual)
+	}
+}
@@ -0,0 +1,75 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// The keys of the Metadata rendered in the header.
+var glogHeaderKeys = map[string]bool{
+	"file": true,
+	"line": true,
+	"pid":  true,
+}
+
+type glogFormatter struct {
+	pid string
+}
+
+// Returns a Formatter that renders each LogMessage with a header compatible
+// with glog. Format is:
+//	Lmmdd hh:mm:ss.uuuuuu ppppppp file:line] msg
+// where L is the first letter of the name of the level. As in glog, the pid is
+// padded with spaces to 7 characters, and the file and line are "???" and 1 if
+// they are not present in the Metadata. The pid is taken from the Metadata if
+// present, otherwise it is the pid of this process.
+// The remaining Metadata follows the message as "key=value" in sorted order.
+func NewGlogFormatter() Formatter {
+	return &glogFormatter{fmt.Sprintf("%7d", os.Getpid())}
+}
+
+func (f *glogFormatter) Format(m *LogMessage) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(levelName(m.Level)[:1])
+	buf.WriteString(m.Nanoseconds.Format("0102 15:04:05.000000 "))
+
+	if pid, ok := m.Metadata["pid"]; ok {
+		fmt.Fprintf(&buf, "%7s", pid)
+	} else {
+		buf.WriteString(f.pid)
+	}
+	buf.WriteString(" ")
+
+	if file, ok := m.Metadata["file"]; ok {
+		buf.WriteString(file)
+	} else {
+		buf.WriteString("???")
+	}
+	buf.WriteString(":")
+	if line, ok := m.Metadata["line"]; ok {
+		buf.WriteString(line)
+	} else {
+		buf.WriteString("1")
+	}
+
+	buf.WriteString("] ")
+	buf.WriteString(m.Message)
+	renderFields(&buf, m, glogHeaderKeys)
+	return buf.Bytes()
+}
+
+// Returns a LogOuter that writes each LogMessage to the io.Writer with a glog
+// compatible header (see NewGlogFormatter for the format).
+func NewGlogWriterLogOuter(w io.Writer) LogOuter {
+	return NewWriterLogOuterWithFormatter(w, NewGlogFormatter())
+}
+
+// Returns a LogOuter that appends each LogMessage to the file with a glog
+// compatible header (see NewGlogFormatter for the format), or an error if the
+// file cannot be opened.
+func NewGlogFileLogOuter(filename string) (LogOuter, error) {
+	return NewFileLogOuterWithFormatter(filename, NewGlogFormatter())
+}
@@ -2,7 +2,9 @@
 package golog
 
 import (
+	"context"
 	"flag"
+	"io"
 )
 
 var Global *PackageLogger
@@ -20,14 +22,45 @@ func init() {
 
 	// Prints everything this level and above. 
 	flag.Var(logger, "golog.minloglevel",
-		"Log messages at or above this level. The "+
-			"numbers of severity levels INFO, WARNING, "+
-			"ERROR, and FATAL are 0, 1, 2, and 3, respectively")
+		"Log messages at or above this level, given by name or "+
+			"number. The numbers -2, -1, 0, 1, 2, and 3 are read as "+
+			"the severity levels TRACE, DEBUG, INFO, WARNING, ERROR, "+
+			"and FATAL, respectively")
 
 	Global.logger = NewLocationLogger(logger,
 		MakeMetadataFunc(DefaultMetadata))
 }
 
+// Wrapper for Global.Trace().
+func Trace(msg ...interface{}) {
+	Global.logger.LogDepth(TRACE, printClosure(msg...), 1)
+}
+
+// Wrapper for Global.Tracef().
+func Tracef(fmt string, vals ...interface{}) {
+	Global.logger.LogDepth(TRACE, printfClosure(fmt, vals...), 1)
+}
+
+// Wrapper for Global.Tracec().
+func Tracec(closure func() string) {
+	Global.logger.LogDepth(TRACE, closure, 1)
+}
+
+// Wrapper for Global.Debug().
+func Debug(msg ...interface{}) {
+	Global.logger.LogDepth(DEBUG, printClosure(msg...), 1)
+}
+
+// Wrapper for Global.Debugf().
+func Debugf(fmt string, vals ...interface{}) {
+	Global.logger.LogDepth(DEBUG, printfClosure(fmt, vals...), 1)
+}
+
+// Wrapper for Global.Debugc().
+func Debugc(closure func() string) {
+	Global.logger.LogDepth(DEBUG, closure, 1)
+}
+
 // Wrapper for Global.Info().
 func Info(msg ...interface{}) {
 	Global.logger.LogDepth(INFO, printClosure(msg...), 1)
@@ -91,6 +124,96 @@ func Fatalc(closure func() string) {
 	Global.logger.FailNow()
 }
 
+// Wrapper for Global.Tracew().
+func Tracew(msg string, keysAndValues ...interface{}) {
+	Global.logger.LogDepthFields(TRACE, printClosure(msg),
+		makeFields(keysAndValues), 1)
+}
+
+// Wrapper for Global.Debugw().
+func Debugw(msg string, keysAndValues ...interface{}) {
+	Global.logger.LogDepthFields(DEBUG, printClosure(msg),
+		makeFields(keysAndValues), 1)
+}
+
+// Wrapper for Global.Infow().
+func Infow(msg string, keysAndValues ...interface{}) {
+	Global.logger.LogDepthFields(INFO, printClosure(msg),
+		makeFields(keysAndValues), 1)
+}
+
+// Wrapper for Global.Warningw().
+func Warningw(msg string, keysAndValues ...interface{}) {
+	Global.logger.LogDepthFields(WARNING, printClosure(msg),
+		makeFields(keysAndValues), 1)
+}
+
+// Wrapper for Global.Errorw().
+func Errorw(msg string, keysAndValues ...interface{}) {
+	Global.logger.LogDepthFields(ERROR, printClosure(msg),
+		makeFields(keysAndValues), 1)
+}
+
+// Wrapper for Global.Fatalw().
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	Global.logger.LogDepthFields(FATAL, printClosure(msg),
+		makeFields(keysAndValues), 1)
+	Global.logger.FailNow()
+}
+
+// Wrapper for FromContext(ctx).TraceCtx().
+func TraceCtx(ctx context.Context, msg ...interface{}) {
+	logger := FromContext(ctx)
+	logger.logger.LogDepthFields(TRACE, printClosure(msg...),
+		contextFields(ctx), 1)
+}
+
+// Wrapper for FromContext(ctx).DebugCtx().
+func DebugCtx(ctx context.Context, msg ...interface{}) {
+	logger := FromContext(ctx)
+	logger.logger.LogDepthFields(DEBUG, printClosure(msg...),
+		contextFields(ctx), 1)
+}
+
+// Wrapper for FromContext(ctx).InfoCtx().
+func InfoCtx(ctx context.Context, msg ...interface{}) {
+	logger := FromContext(ctx)
+	logger.logger.LogDepthFields(INFO, printClosure(msg...),
+		contextFields(ctx), 1)
+}
+
+// Wrapper for FromContext(ctx).WarningCtx().
+func WarningCtx(ctx context.Context, msg ...interface{}) {
+	logger := FromContext(ctx)
+	logger.logger.LogDepthFields(WARNING, printClosure(msg...),
+		contextFields(ctx), 1)
+}
+
+// Wrapper for FromContext(ctx).ErrorCtx().
+func ErrorCtx(ctx context.Context, msg ...interface{}) {
+	logger := FromContext(ctx)
+	logger.logger.LogDepthFields(ERROR, printClosure(msg...),
+		contextFields(ctx), 1)
+}
+
+// Wrapper for FromContext(ctx).FatalCtx().
+func FatalCtx(ctx context.Context, msg ...interface{}) {
+	logger := FromContext(ctx)
+	logger.logger.LogDepthFields(FATAL, printClosure(msg...),
+		contextFields(ctx), 1)
+	logger.logger.FailNow()
+}
+
+// Wrapper for Global.With().
+func With(keysAndValues ...interface{}) *PackageLogger {
+	return Global.With(keysAndValues...)
+}
+
+// Wrapper for Global.V().
+func V(level int) Verbose {
+	return Global.v(level, 1)
+}
+
 // Wrapper for Global.StartTestLogging().
 func StartTestLogging(t TestController) {
 	Global.StartTestLogging(t)
@@ -111,6 +234,21 @@ func RemoveLogOuter(key string) {
 	Global.RemoveLogOuter(key)
 }
 
+// Wrapper for Global.Flush().
+func Flush() error {
+	return Global.Flush()
+}
+
+// Wrapper for Global.Close(). Also closes every file added via the
+// golog.logfile flag, which are shared by every default PackageLogger.
+func Close() error {
+	err := Global.Close()
+	if closeErr := defaultLogOuters.(io.Closer).Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
 // Wrapper for Global.SetMinLogLevel().
 func SetMinLogLevel(level int) {
 	Global.SetMinLogLevel(level)
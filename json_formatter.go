@@ -0,0 +1,113 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type jsonFormatter struct{}
+
+// Returns a Formatter that renders each LogMessage as a single line JSON
+// object. The schema is:
+//	{"level":"ERROR","levelnum":200,"time":"{RFC3339Nano}","message":"...",
+//	 "file":"foo.go","line":"12",...}
+// where every key in the Metadata follows the fixed fields in sorted order.
+// The Stack, if recorded, is an array with one string per frame under the key
+// "stack", replacing any "stack" in the Metadata, and the pid, goroutine, and
+// seq are numbers.
+// Metadata keys that collide with one of the fixed fields are dropped.
+func NewJSONFormatter() Formatter {
+	return jsonFormatter{}
+}
+
+var jsonReservedKeys = map[string]bool{
+	"level":    true,
+	"levelnum": true,
+	"time":     true,
+	"message":  true,
+}
+
+// The keys of the Metadata rendered as numbers, if they are integers.
+var jsonNumericKeys = map[string]bool{
+	"pid":       true,
+	"goroutine": true,
+	"seq":       true,
+}
+
+// Writes the string to the buffer as a quoted JSON string.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	// Marshalling a string never fails.
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
+
+// Writes the value of the key in the Metadata to the buffer as JSON.
+func writeJSONMetadata(buf *bytes.Buffer, m *LogMessage, key string) {
+	value := m.Metadata[key]
+
+	if key == stackKey && len(m.Stack) > 0 {
+		buf.WriteString("[")
+		for i, frame := range m.Stack {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			writeJSONString(buf, frame)
+		}
+		buf.WriteString("]")
+	} else if _, err := strconv.ParseUint(value, 10, 64); err == nil && jsonNumericKeys[key] {
+		buf.WriteString(value)
+	} else {
+		writeJSONString(buf, value)
+	}
+}
+
+func (f jsonFormatter) Format(m *LogMessage) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(`{"level":`)
+	writeJSONString(&buf, levelName(m.Level))
+	buf.WriteString(`,"levelnum":`)
+	buf.WriteString(strconv.Itoa(m.Level))
+	buf.WriteString(`,"time":`)
+	writeJSONString(&buf, m.Nanoseconds.Format(time.RFC3339Nano))
+	buf.WriteString(`,"message":`)
+	writeJSONString(&buf, m.Message)
+
+	keys := make([]string, 0, len(m.Metadata))
+	for key, _ := range m.Metadata {
+		if !jsonReservedKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	if _, ok := m.Metadata[stackKey]; !ok && len(m.Stack) > 0 {
+		keys = append(keys, stackKey)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		buf.WriteString(",")
+		writeJSONString(&buf, key)
+		buf.WriteString(":")
+		writeJSONMetadata(&buf, m, key)
+	}
+
+	buf.WriteString("}")
+	return buf.Bytes()
+}
+
+// Returns a LogOuter that writes each LogMessage to the io.Writer as a line
+// of JSON (see NewJSONFormatter for the schema).
+func NewJSONWriterLogOuter(w io.Writer) LogOuter {
+	return NewWriterLogOuterWithFormatter(w, NewJSONFormatter())
+}
+
+// Returns a LogOuter that appends each LogMessage to the file as a line of
+// JSON (see NewJSONFormatter for the schema), or an error if the file cannot
+// be opened.
+func NewJSONFileLogOuter(filename string) (LogOuter, error) {
+	return NewFileLogOuterWithFormatter(filename, NewJSONFormatter())
+}
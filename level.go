@@ -0,0 +1,125 @@
+package golog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Higher levels are more severe. The levels are spaced apart so that levels
+// registered with RegisterLevel can fall between them, and TRACE and DEBUG are
+// more verbose than INFO, so they are negative.
+const (
+	TRACE   int = -200
+	DEBUG   int = -100
+	INFO    int = 0
+	WARNING int = 100
+	ERROR   int = 200
+	FATAL   int = 300
+)
+
+// The levels numbered -2 through 3 before they were spaced apart, so that
+// numeric minloglevel flags keep their meaning.
+var legacyLevels = map[int]int{
+	-2: TRACE,
+	-1: DEBUG,
+	0:  INFO,
+	1:  WARNING,
+	2:  ERROR,
+	3:  FATAL,
+}
+
+var levelLock sync.RWMutex
+
+var levelNames = map[int]string{
+	TRACE:   "TRACE",
+	DEBUG:   "DEBUG",
+	INFO:    "INFO",
+	WARNING: "WARNING",
+	ERROR:   "ERROR",
+	FATAL:   "FATAL",
+}
+
+// Names are matched case insensitively, so they are stored in upper case.
+var levelsByName = map[string]int{
+	"TRACE":   TRACE,
+	"DEBUG":   DEBUG,
+	"INFO":    INFO,
+	"WARNING": WARNING,
+	"ERROR":   ERROR,
+	"FATAL":   FATAL,
+}
+
+// Associates the name with the level, so that formatters print the name and
+// the level can be parsed from the name (for example, by the minloglevel
+// flag). Higher levels are more severe, and a new level can fall between the
+// predefined ones. Returns an error if either the level or the name (case
+// insensitively) is already registered. For example,
+//	const NOTICE = golog.INFO + 50
+//	
+//	func init() {
+//		golog.RegisterLevel(NOTICE, "NOTICE")
+//	}
+func RegisterLevel(level int, name string) error {
+	levelLock.Lock()
+	defer levelLock.Unlock()
+
+	upper := strings.ToUpper(name)
+	if name == "" {
+		return fmt.Errorf("level name must not be empty")
+	}
+	if old, ok := levelNames[level]; ok {
+		return fmt.Errorf("level %d already registered as %s", level, old)
+	}
+	if old, ok := levelsByName[upper]; ok {
+		return fmt.Errorf("level name %s already registered as %d", name, old)
+	}
+
+	levelNames[level] = name
+	levelsByName[upper] = level
+	return nil
+}
+
+// Removes the name of the level, so that tests can register levels repeatedly.
+func unregisterLevel(level int) {
+	levelLock.Lock()
+	defer levelLock.Unlock()
+
+	if name, ok := levelNames[level]; ok {
+		delete(levelsByName, strings.ToUpper(name))
+		delete(levelNames, level)
+	}
+}
+
+// Returns the name of the level, or "L{level}" if the level has no name.
+func levelName(level int) string {
+	levelLock.RLock()
+	defer levelLock.RUnlock()
+
+	if name, ok := levelNames[level]; ok {
+		return name
+	}
+	return fmt.Sprintf("L%d", level)
+}
+
+// Returns the level with the name (case insensitively), or the level if val
+// is a number. The numbers -2 through 3 are read as TRACE through FATAL, which
+// they were before the levels were spaced apart. Returns an error if val is
+// neither a name nor a number.
+func ParseLevel(val string) (int, error) {
+	if level, err := strconv.Atoi(val); err == nil {
+		if legacy, ok := legacyLevels[level]; ok {
+			return legacy, nil
+		}
+		return level, nil
+	}
+
+	levelLock.RLock()
+	defer levelLock.RUnlock()
+
+	if level, ok := levelsByName[strings.ToUpper(val)]; ok {
+		return level, nil
+	}
+	return 0, fmt.Errorf("unknown level %q", val)
+}
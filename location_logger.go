@@ -1,6 +1,8 @@
 package golog
 
 import (
+	"strconv"
+	"sync/atomic"
 	"time"
 )
 
@@ -9,9 +11,15 @@ import (
 type LocationLogger interface {
 	Logger
 	LogDepth(level int, closure func() string, depth int)
+	// Like LogDepth, but also adds the fields to the Metadata. The fields
+	// are only rendered if the message will be logged.
+	LogDepthFields(level int, closure func() string, fields []Field, depth int)
 }
 
 type locationLoggerImpl struct {
+	// The last sequence number, accessed atomically. First to ensure 64
+	// bit alignment.
+	sequence uint64
 	Logger
 	getMetadata MetadataFunc
 }
@@ -20,7 +28,7 @@ type locationLoggerImpl struct {
 // the provided function to generate the metadata. For example:
 //	log := NewLocationLogger(NewDefaultLogger(), NoLocation)
 func NewLocationLogger(l Logger, metadataFunc MetadataFunc) LocationLogger {
-	return &locationLoggerImpl{l, metadataFunc}
+	return &locationLoggerImpl{Logger: l, getMetadata: metadataFunc}
 }
 
 // Returns a LocationLogger wrapping the DefaultLogger. 
@@ -29,22 +37,79 @@ func NewDefaultLocationLogger() LocationLogger {
 		MakeMetadataFunc(DefaultMetadata))
 }
 
-func (l *locationLoggerImpl) makeLogClosure(level int, msg func() string, skip int) func() *LogMessage {
+func (l *locationLoggerImpl) makeLogClosure(level int, msg func() string, fields []Field, skip int) func() *LogMessage {
 	// Evaluate this early.
 	ns := time.Now()
 	// TODO(awreece) Add ns to metadata?
-	metadata := l.getMetadata(skip + 1)
+	m := &LogMessage{
+		Level:       level,
+		Nanoseconds: ns,
+		Metadata:    make(map[string]string),
+	}
+	l.getMetadata(skip+1, m)
+	stack := requestedStack(m, skip+1)
+	location, backtrace := backtraceAt.at(skip + 1)
 
 	return func() *LogMessage {
-		return &LogMessage{
-			Level:       level,
-			Message:     msg(),
-			Nanoseconds: ns,
-			Metadata:    metadata,
+		m.Stack = formatStack(stack)
+		if m.requests&SequenceNumber > 0 {
+			m.Metadata[sequenceKey] = strconv.FormatUint(
+				atomic.AddUint64(&l.sequence, 1), 10)
+		}
+		addProvidedMetadata(m.Metadata)
+		addFields(m.Metadata, fields)
+		m.Message = msg()
+		if backtrace {
+			// We are still on the goroutine of the caller.
+			m.Message += "\n" + string(callerStackTrace(location))
 		}
+		return m
 	}
 }
 
 func (l *locationLoggerImpl) LogDepth(level int, closure func() string, depth int) {
-	l.Log(level, l.makeLogClosure(level, closure, depth+1))
+	l.logDepth(level, closure, nil, depth+1)
+}
+
+func (l *locationLoggerImpl) LogDepthFields(level int, closure func() string, fields []Field, depth int) {
+	l.logDepth(level, closure, fields, depth+1)
+}
+
+func (l *locationLoggerImpl) logDepth(level int, closure func() string, fields []Field, skip int) {
+	// A per module override replaces the minloglevel of the Logger.
+	if minloglevel, ok := vmodule.lookup(skip + 1); ok {
+		if level < minloglevel {
+			return
+		}
+		l.logDepthUnfiltered(level, closure, fields, skip+1)
+		return
+	}
+
+	l.Log(level, l.makeLogClosure(level, closure, fields, skip+1))
+}
+
+// Implemented by LocationLoggers that can log a message regardless of the
+// minloglevel of their Logger, such as for enabled V logging.
+type unfilteredLocationLogger interface {
+	logDepthUnfiltered(level int, closure func() string, fields []Field, skip int)
+}
+
+// Skip 0 refers to the function calling this function.
+func (l *locationLoggerImpl) logDepthUnfiltered(level int, closure func() string, fields []Field, skip int) {
+	logClosure := l.makeLogClosure(level, closure, fields, skip+1)
+	if unfiltered, ok := l.Logger.(unfilteredLogger); ok {
+		unfiltered.logUnfiltered(logClosure)
+	} else {
+		l.Log(level, logClosure)
+	}
+}
+
+// Logs the message regardless of the minloglevel, if the LocationLogger
+// supports it. Skip 0 refers to the function calling this function.
+func logUnfiltered(l LocationLogger, level int, closure func() string, fields []Field, skip int) {
+	if unfiltered, ok := l.(unfilteredLocationLogger); ok {
+		unfiltered.logDepthUnfiltered(level, closure, fields, skip+1)
+	} else {
+		l.LogDepthFields(level, closure, fields, skip+1)
+	}
 }
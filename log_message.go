@@ -2,12 +2,13 @@ package golog
 
 import (
 	"bytes"
-	"fmt"
 	"os"
 	"path"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,27 +20,50 @@ type LogMessage struct {
 	// By convention, fields in this map will be entirely lowercase and
 	// single word.
 	Metadata map[string]string
+	// The stack of the caller, one "{function} {file}:{line}" frame per
+	// element, if recorded (see Stack).
+	Stack []string `json:",omitempty"`
+	// The data requested by the MetadataFunc that the LocationLogger only
+	// records if the message is output, such as Stack.
+	requests LocationFlag
 }
 
 // TODO(awreece) comment this
 // Skip 0 refers to the function calling this function.
-// Walks up the stack skip frames and returns the metatdata for that frame.
-type MetadataFunc func(skip int) map[string]string
+// Walks up the stack skip frames and adds the metadata for that frame to the
+// LogMessage, whose Metadata is already allocated.
+type MetadataFunc func(skip int, m *LogMessage)
 
-var NoLocation MetadataFunc = func(skip int) map[string]string {
+var NoLocation MetadataFunc = func(skip int, m *LogMessage) {
 	// TODO(awreece) Add timestamp?
-	return make(map[string]string)
 }
 
 type LocationFlag int
 
 const (
 	None LocationFlag = 1 << iota
+	// The full import path of the package of the caller, such as
+	// "github.com/awreece/golog". Before the function names were cached,
+	// this was the text before the first dot of the function name, which is
+	// "github" for that package.
 	Package
 	Function
 	File
 	Line
 	Hostname
+	// The stack of the caller, recorded only for messages at or above the
+	// level set by the golog.stack_minloglevel flag (ERROR by default).
+	// Not included in All.
+	Stack
+	// The id of this process.
+	Pid
+	// The id of the calling goroutine, as in stack traces. The runtime
+	// doesn't expose it, so it is parsed from runtime.Stack, which costs
+	// several microseconds for every message, even those not output.
+	GoroutineID
+	// A number incremented for each message output by the LocationLogger,
+	// for detecting reordered or dropped messages.
+	SequenceNumber
 	DefaultMetadata = File | Line
 	All             = Package | Function | File | Line | Hostname
 	requiresPC      = Package | Function | File | Line
@@ -51,8 +75,10 @@ const (
 // flags is the set of locations to add to the metadata. For example, 
 //	MakeMetadataFunc(File | Line | Hostname)
 func MakeMetadataFunc(flags LocationFlag) MetadataFunc {
-	return func(skip int) map[string]string {
-		ret := NoLocation(skip + 1)
+	pid := strconv.Itoa(os.Getpid())
+
+	return func(skip int, m *LogMessage) {
+		ret := m.Metadata
 
 		// TODO(awreece) Refactor.
 		if flags&requiresPC > 0 {
@@ -60,16 +86,12 @@ func MakeMetadataFunc(flags LocationFlag) MetadataFunc {
 			if pc, file, line, ok := runtime.Caller(skip + 1); ok {
 				// Don't get FuncForPC unless we have to.
 				if flags&Package > 0 || flags&Function > 0 {
-					// TODO(awreece) Make sure this is 
-					// compiler agnostic.
-					funcParts := strings.SplitN(
-						runtime.FuncForPC(pc).Name(),
-						".", 2)
+					name := lookupFuncName(pc)
 					if flags&Package > 0 {
-						ret["package"] = funcParts[0]
+						ret["package"] = name.pkg
 					}
 					if flags&Function > 0 {
-						ret["function"] = funcParts[1]
+						ret["function"] = name.function
 					}
 				}
 
@@ -83,68 +105,119 @@ func MakeMetadataFunc(flags LocationFlag) MetadataFunc {
 			}
 		}
 		if flags&Hostname > 0 {
-			if host, err := os.Hostname(); err == nil {
+			if host := cachedHostname(); host != "" {
 				ret["hostname"] = host
 			}
 		}
-		return ret
+		if flags&Stack > 0 {
+			// Request the stack, the LocationLogger knows the level.
+			m.requests |= Stack
+		}
+		if flags&Pid > 0 {
+			ret["pid"] = pid
+		}
+		if flags&GoroutineID > 0 {
+			ret["goroutine"] = goroutineID()
+		}
+		if flags&SequenceNumber > 0 {
+			// Request the sequence number, the LocationLogger assigns
+			// it if the message is output.
+			m.requests |= SequenceNumber
+		}
 	}
 }
 
-// Render the formatted metadata to the buffer. If all present, format is 
-// "{time} {pack}.{func}/{file}:{line}". If some fields omitted, intelligently
-// delimits the remaining fields.
-func renderMetadata(buf *bytes.Buffer, m *LogMessage) {
-	if m == nil {
-		// TODO(awreece) Panic here?
-		return
+// The package and function of a pc, as split by splitFuncName, and the file
+// containing it.
+type funcName struct {
+	pkg      string
+	function string
+	file     string
+}
+
+var funcNameLock sync.RWMutex
+
+// A cache from the pc of a call site to the name of the function. Entries are
+// never evicted, so the cache grows to one entry for every call site that has
+// logged with Package or Function in the metadata.
+var funcNames = make(map[uintptr]funcName)
+
+// Returns the package, function and file of the pc, as returned by
+// runtime.Caller.
+func lookupFuncName(pc uintptr) funcName {
+	funcNameLock.RLock()
+	name, ok := funcNames[pc]
+	funcNameLock.RUnlock()
+	if ok {
+		return name
 	}
 
-	buf.WriteString(m.Nanoseconds.Format(" 15:04:05.000000"))
+	if f := runtime.FuncForPC(pc); f != nil {
+		name.pkg, name.function = splitFuncName(f.Name())
+		name.file, _ = f.FileLine(pc)
+	}
 
-	packName, packPresent := m.Metadata["package"]
-	file, filePresent := m.Metadata["file"]
-	funcName, funcPresent := m.Metadata["function"]
-	line, linePresent := m.Metadata["line"]
+	funcNameLock.Lock()
+	defer funcNameLock.Unlock()
 
-	if packPresent || filePresent || funcPresent || linePresent {
-		buf.WriteString(" ")
-	}
+	funcNames[pc] = name
+	return name
+}
 
-	// TODO(awreece) This logic is terrifying.
-	if packPresent {
-		buf.WriteString(packName)
+// Splits the name of a function as returned by runtime.FuncForPC into the
+// import path of the package and the name of the function. For example,
+// "github.com/awreece/golog.(*PackageLogger).Info" is split into
+// "github.com/awreece/golog" and "(*PackageLogger).Info". Dots in the last
+// element of the import path are escaped as "%2e" by the linker.
+func splitFuncName(name string) (pkg, function string) {
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+		dot += slash + 1
+		return strings.Replace(name[:dot], "%2e", ".", -1), name[dot+1:]
 	}
-	if funcPresent {
-		if packPresent {
-			buf.WriteString(".")
-		}
-		buf.WriteString(funcName)
-	}
-	if (packPresent || funcPresent) && (filePresent || linePresent) {
-		buf.WriteString("/")
+	return name, ""
+}
+
+// The keys of the Metadata rendered before the message by the DefaultFormatter.
+var locationKeys = map[string]bool{
+	"file": true,
+	"line": true,
+}
+
+// The key of the Metadata holding the sequence number.
+const sequenceKey = "seq"
+
+// Returns the id of the calling goroutine, as in stack traces. The runtime
+// deliberately doesn't expose it, so parse the header of the stack trace,
+// which is "goroutine {id} [{status}]:".
+func goroutineID() string {
+	var buf [64]byte
+	stack := buf[:runtime.Stack(buf[:], false)]
+	if bytes.HasPrefix(stack, []byte("goroutine ")) {
+		stack = stack[len("goroutine "):]
 	}
-	if filePresent {
-		buf.WriteString(file)
+	if space := bytes.IndexByte(stack, ' '); space > 0 {
+		return string(stack[:space])
 	}
-	if linePresent {
-		if filePresent {
-			buf.WriteString(":")
+	return "0"
+}
+
+// Render every key in the Metadata not in skip to the buffer, in sorted order,
+// as " {key}={value}" with the value quoted if necessary. The Stack, if
+// recorded, follows as an indented block.
+func renderFields(buf *bytes.Buffer, m *LogMessage, skip map[string]bool) {
+	keys := make([]string, 0, len(m.Metadata))
+	for key, _ := range m.Metadata {
+		if !skip[key] {
+			keys = append(keys, key)
 		}
-		buf.WriteString(line)
 	}
-}
+	sort.Strings(keys)
 
-// Format the message as a string, optionally inserting a newline.
-// Format is: "L{level} {time} {pack}.{func}/{file}:{line}] {message}"
-func formatLogMessage(m *LogMessage, insertNewline bool) string {
-	var buf bytes.Buffer
-	buf.WriteString(fmt.Sprintf("L%d", m.Level))
-	renderMetadata(&buf, m)
-	buf.WriteString("] ")
-	buf.WriteString(m.Message)
-	if insertNewline {
-		buf.WriteString("\n")
+	for _, key := range keys {
+		buf.WriteString(" ")
+		writeLogfmtPair(buf, key, m.Metadata[key])
 	}
-	return buf.String()
+
+	renderStack(buf, m)
 }
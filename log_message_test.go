@@ -0,0 +1,110 @@
+package golog
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestMakeMetadataFunc(t *testing.T) {
+	m := &LogMessage{Metadata: make(map[string]string)}
+	MakeMetadataFunc(All)(0, m)
+	metadata := m.Metadata
+
+	if metadata["package"] != testPackage {
+		t.Errorf("Expected package %s, got %q", testPackage,
+			metadata["package"])
+	}
+	if metadata["function"] != "TestMakeMetadataFunc" {
+		t.Errorf("Expected function TestMakeMetadataFunc, got %q",
+			metadata["function"])
+	}
+	if metadata["file"] != "log_message_test.go" {
+		t.Errorf("Expected file log_message_test.go, got %q",
+			metadata["file"])
+	}
+	if host, err := os.Hostname(); err == nil && metadata["hostname"] != host {
+		t.Errorf("Expected hostname %q, got %q", host,
+			metadata["hostname"])
+	}
+}
+
+func TestSplitFuncName(t *testing.T) {
+	tests := []struct{ name, pkg, function string }{
+		{"main.main", "main", "main"},
+		{"github.com/awreece/golog.(*PackageLogger).Info",
+			"github.com/awreece/golog", "(*PackageLogger).Info"},
+		{"gopkg.in/yaml%2ev2.Unmarshal", "gopkg.in/yaml.v2", "Unmarshal"},
+		{"github.com/a/b.F.func1", "github.com/a/b", "F.func1"},
+	}
+
+	for _, test := range tests {
+		pkg, function := splitFuncName(test.name)
+		if pkg != test.pkg || function != test.function {
+			t.Errorf("Expected %q to split into %q and %q, got %q and %q",
+				test.name, test.pkg, test.function, pkg, function)
+		}
+	}
+}
+
+func TestSequenceNumber(t *testing.T) {
+	logger, received := newChanLogger(2, INFO,
+		MakeMetadataFunc(Pid|GoroutineID|SequenceNumber))
+
+	logger.Debug("filtered")
+	logger.Info("first")
+	logger.With("k", "v").Info("second")
+
+	first, second := receive(t, received), receive(t, received)
+	if first.Metadata["seq"] != "1" || second.Metadata["seq"] != "2" {
+		t.Errorf("Expected seq 1 and 2, got %v and %v",
+			first.Metadata, second.Metadata)
+	}
+	if first.Metadata["pid"] == "" || first.Metadata["goroutine"] == "" ||
+		first.Metadata["goroutine"] == "0" {
+		t.Errorf("Expected pid and goroutine, got %v", first.Metadata)
+	}
+}
+
+func BenchmarkMetadataDefault(b *testing.B) {
+	metadataFunc := MakeMetadataFunc(DefaultMetadata)
+	for i := 0; i < b.N; i++ {
+		metadataFunc(0, &LogMessage{Metadata: make(map[string]string)})
+	}
+}
+
+func BenchmarkMetadataAll(b *testing.B) {
+	metadataFunc := MakeMetadataFunc(All)
+	for i := 0; i < b.N; i++ {
+		metadataFunc(0, &LogMessage{Metadata: make(map[string]string)})
+	}
+}
+
+func BenchmarkMetadataHostname(b *testing.B) {
+	metadataFunc := MakeMetadataFunc(Hostname)
+	for i := 0; i < b.N; i++ {
+		metadataFunc(0, &LogMessage{Metadata: make(map[string]string)})
+	}
+}
+
+func BenchmarkLogAll(b *testing.B) {
+	logger := NewPackageLogger(NewMultiLogOuter(), INFO, nil,
+		MakeMetadataFunc(All))
+	for i := 0; i < b.N; i++ {
+		logger.Info("hello")
+	}
+}
+
+func BenchmarkFuncNameUncached(b *testing.B) {
+	pc, _, _, _ := runtime.Caller(0)
+	for i := 0; i < b.N; i++ {
+		splitFuncName(runtime.FuncForPC(pc).Name())
+	}
+}
+
+func BenchmarkFuncNameCached(b *testing.B) {
+	pc, _, _, _ := runtime.Caller(0)
+	for i := 0; i < b.N; i++ {
+		lookupFuncName(pc)
+	}
+}
@@ -22,38 +22,99 @@ type LogOuter interface {
 	Output(*LogMessage)
 }
 
+// A Flusher is a LogOuter that buffers output. LogOuters that own resources
+// should also implement io.Closer; a LogOuter must not be used after it is
+// closed.
+type Flusher interface {
+	// Flush any buffered output. Flush must be safe to call concurrently
+	// with Output.
+	Flush() error
+}
+
 type writerLogOuter struct {
 	lock sync.Mutex
 	io.Writer
+	formatter Formatter
+	// Only set if we own the io.Writer, in which case it is closed by
+	// Close().
+	closer io.Closer
 }
 
 func (f *writerLogOuter) Output(m *LogMessage) {
+	// Format outside the lock, only the write needs to be serialized.
+	// Make sure to insert a newline.
+	line := append(f.formatter.Format(m), '\n')
+
 	f.lock.Lock()
 	defer f.lock.Unlock()
 
 	// TODO(awreece) Handle short write?
-	// Make sure to insert a newline.
-	f.Write([]byte(formatLogMessage(m, true)))
+	f.Write(line)
 }
 
-// Returns a LogOuter wrapping the io.Writer.
+// Implements Flusher.Flush() by flushing the io.Writer, if it is a Flusher.
+func (f *writerLogOuter) Flush() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if flusher, ok := f.Writer.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// Implements io.Closer.Close(). Only closes the io.Writer if it was opened by
+// this package.
+func (f *writerLogOuter) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.closer != nil {
+		return f.closer.Close()
+	}
+	return nil
+}
+
+// Returns a LogOuter wrapping the io.Writer. The io.Writer is not closed when
+// the LogOuter is closed.
 func NewWriterLogOuter(f io.Writer) LogOuter {
-	return &writerLogOuter{io.Writer: f}
+	return NewWriterLogOuterWithFormatter(f, DefaultFormatter)
+}
+
+// Returns a LogOuter wrapping the io.Writer that renders each LogMessage
+// with the provided Formatter.
+func NewWriterLogOuterWithFormatter(f io.Writer, formatter Formatter) LogOuter {
+	return &writerLogOuter{Writer: f, formatter: formatter}
 }
 
 // Returns a LogOuter wrapping the file, or an error if the file cannot be
 // opened.
 func NewFileLogOuter(filename string) (LogOuter, error) {
-	// TODO(awreece) Permissions?
-	if file, err := os.OpenFile(filename, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0666); err != nil {
+	return NewFileLogOuterWithFormatter(filename, DefaultFormatter)
+}
+
+// Returns a LogOuter wrapping the file that renders each LogMessage with the
+// provided Formatter, or an error if the file cannot be opened.
+func NewFileLogOuterWithFormatter(filename string, formatter Formatter) (LogOuter, error) {
+	if file, err := openLogFile(filename); err != nil {
 		return nil, err
 	} else {
-		return NewWriterLogOuter(file), nil
+		return &writerLogOuter{
+			Writer:    file,
+			formatter: formatter,
+			closer:    file,
+		}, nil
 	}
 
 	panic("Code never reaches here, this mollifies the compiler.")
 }
 
+// Opens the file for appending, creating it if necessary.
+func openLogFile(filename string) (*os.File, error) {
+	// TODO(awreece) Permissions?
+	return os.OpenFile(filename, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0666)
+}
+
 // We want to allow an abitrary testing framework.
 type TestController interface {
 	// We will assume that testers insert newlines in manner similar to 
@@ -64,17 +125,24 @@ type TestController interface {
 
 type testLogOuter struct {
 	TestController
+	formatter Formatter
 }
 
 func (t *testLogOuter) Output(m *LogMessage) {
 	// Don't insert an additional log message since the tester inserts them
 	// for us.
-	t.Log(formatLogMessage(m, false))
+	t.Log(string(t.formatter.Format(m)))
 }
 
 // Return a LogOuter wrapping the TestControlller.
 func NewTestLogOuter(t TestController) LogOuter {
-	return &testLogOuter{t}
+	return NewTestLogOuterWithFormatter(t, DefaultFormatter)
+}
+
+// Return a LogOuter wrapping the TestController that renders each LogMessage
+// with the provided Formatter.
+func NewTestLogOuterWithFormatter(t TestController, formatter Formatter) LogOuter {
+	return &testLogOuter{t, formatter}
 }
 
 type udpLogOuter struct {
@@ -83,7 +151,7 @@ type udpLogOuter struct {
 }
 
 // Returns a LogOuter that forwards LogMessages in json format to UDP network
-// address. TODO(awreece): Use protobuf?
+// address. They can be received with a Collector. TODO(awreece): Use protobuf?
 func NewUDPLogOuter(raddr string) (LogOuter, error) {
 	var addr *net.UDPAddr
 	var err error
@@ -93,7 +161,8 @@ func NewUDPLogOuter(raddr string) (LogOuter, error) {
 		return nil, err
 	}
 
-	if conn, err = net.DialUDP("udp", nil, addr); err != nil {
+	// The socket must not be connected, as we send via WriteTo.
+	if conn, err = net.ListenUDP("udp", nil); err != nil {
 		return nil, err
 	}
 
@@ -107,3 +176,8 @@ func (o *udpLogOuter) Output(m *LogMessage) {
 		o.conn.WriteTo(bytes, o.raddr)
 	}
 }
+
+// Implements io.Closer.Close().
+func (o *udpLogOuter) Close() error {
+	return o.conn.Close()
+}
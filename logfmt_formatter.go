@@ -0,0 +1,104 @@
+package golog
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type logfmtFormatter struct{}
+
+// Returns a Formatter that renders each LogMessage as a logfmt line. Format is:
+//	level=ERROR ts={RFC3339Nano} file=foo.go line=12 msg="..."
+// where every key in the Metadata appears between ts and msg in sorted order.
+// Metadata keys that collide with level, ts, or msg are dropped.
+func NewLogfmtFormatter() Formatter {
+	return logfmtFormatter{}
+}
+
+var logfmtReservedKeys = map[string]bool{
+	"level": true,
+	"ts":    true,
+	"msg":   true,
+}
+
+// Returns true if the value must be quoted to be a valid logfmt value.
+func logfmtNeedsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' ||
+			!unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the key with every character not allowed in a logfmt key replaced
+// with an underscore.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// Writes key=value to the buffer, quoting and escaping the value if needed.
+func writeLogfmtPair(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(logfmtKey(key))
+	buf.WriteString("=")
+	if logfmtNeedsQuote(value) {
+		buf.WriteString(strconv.Quote(value))
+	} else {
+		buf.WriteString(value)
+	}
+}
+
+func (f logfmtFormatter) Format(m *LogMessage) []byte {
+	var buf bytes.Buffer
+
+	writeLogfmtPair(&buf, "level", levelName(m.Level))
+	buf.WriteString(" ")
+	writeLogfmtPair(&buf, "ts", m.Nanoseconds.Format(time.RFC3339Nano))
+
+	keys := make([]string, 0, len(m.Metadata))
+	for key, _ := range m.Metadata {
+		if !logfmtReservedKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		buf.WriteString(" ")
+		writeLogfmtPair(&buf, key, m.Metadata[key])
+	}
+
+	buf.WriteString(" ")
+	writeLogfmtPair(&buf, "msg", m.Message)
+	return buf.Bytes()
+}
+
+// Returns a LogOuter that writes each LogMessage to the io.Writer as a logfmt
+// line (see NewLogfmtFormatter for the format).
+func NewLogfmtWriterLogOuter(w io.Writer) LogOuter {
+	return NewWriterLogOuterWithFormatter(w, NewLogfmtFormatter())
+}
+
+// Returns a LogOuter that appends each LogMessage to the file as a logfmt
+// line (see NewLogfmtFormatter for the format), or an error if the file cannot
+// be opened.
+func NewLogfmtFileLogOuter(filename string) (LogOuter, error) {
+	return NewFileLogOuterWithFormatter(filename, NewLogfmtFormatter())
+}
@@ -4,7 +4,6 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"strconv"
 )
 
 var defaultMinLogLevel int = ERROR
@@ -19,7 +18,8 @@ type Logger interface {
 	// If the message is to be logged, evaluates the closure and outputs
 	// the result.
 	Log(level int, closure func() *LogMessage)
-	// Fail and halt standard control flow.
+	// Flush the Logger if possible, then fail and halt standard control
+	// flow.
 	FailNow()
 	// All future calls to log with log only if the message is at 
 	// level or higher.
@@ -71,8 +71,21 @@ func (l *loggerImpl) Log(level int, closure func() *LogMessage) {
 	}
 }
 
+// Implemented by Loggers that can output a message regardless of their
+// minloglevel, so that per module overrides can make a Logger more verbose.
+type unfilteredLogger interface {
+	logUnfiltered(closure func() *LogMessage)
+}
+
+func (l *loggerImpl) logUnfiltered(closure func() *LogMessage) {
+	l.Output(closure())
+}
+
 func (l *loggerImpl) FailNow() {
-	// TODO(awreece) Flush log outer?
+	// Make sure the fatal message is written before we fail.
+	if flusher, ok := l.LogOuter.(Flusher); ok {
+		flusher.Flush()
+	}
 	l.failFunc()
 }
 
@@ -81,7 +94,7 @@ func (l *loggerImpl) SetMinLogLevel(level int) {
 }
 
 func (l *loggerImpl) Set(val string) bool {
-	if ival, err := strconv.Atoi(val); err == nil {
+	if ival, err := ParseLevel(val); err == nil {
 		l.minloglevel = ival
 		return true
 	} else {
@@ -87,3 +87,152 @@ func TestSetMinLogLevel(t *testing.T) {
 		t.Error("Message logged when log level wrong")
 	}
 }
+
+func TestSetFlagByName(t *testing.T) {
+	logger := NewLogger(nil, INFO, nil)
+
+	if !logger.Set("warning") || logger.String() != "100" {
+		t.Errorf("Expected minloglevel 100, got %s", logger.String())
+	}
+	// The numbers the levels had before they were spaced apart.
+	if !logger.Set("3") || logger.String() != "300" {
+		t.Errorf("Expected minloglevel 300, got %s", logger.String())
+	}
+	if !logger.Set("-1") || logger.String() != "-100" {
+		t.Errorf("Expected minloglevel -100, got %s", logger.String())
+	}
+	if !logger.Set("150") || logger.String() != "150" {
+		t.Errorf("Expected minloglevel 150, got %s", logger.String())
+	}
+	if logger.Set("bogus") {
+		t.Error("Set succeeded with unknown level")
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	const NOTICE = INFO + 50
+
+	if err := RegisterLevel(NOTICE, "Notice"); err != nil {
+		t.Fatal("Error registering level:", err)
+	}
+	defer unregisterLevel(NOTICE)
+	if err := RegisterLevel(NOTICE, "Other"); err == nil {
+		t.Error("Registered the same level twice")
+	}
+	if err := RegisterLevel(NOTICE+1, "NOTICE"); err == nil {
+		t.Error("Registered the same name twice")
+	}
+
+	if level, err := ParseLevel("notice"); err != nil || level != NOTICE {
+		t.Errorf("Expected level %d, got %d (%v)", NOTICE, level, err)
+	}
+
+	message := &LogMessage{Level: NOTICE, Message: "hello"}
+	formatter, _ := NewTemplateFormatter("{level}: {message}")
+	if actual := string(formatter.Format(message)); actual != "Notice: hello" {
+		t.Errorf("Expected %q, got %q", "Notice: hello", actual)
+	}
+}
+
+func TestRegisteredLevelOrder(t *testing.T) {
+	const NOTICE = INFO + 50
+
+	if err := RegisterLevel(NOTICE, "NOTICE"); err != nil {
+		t.Fatal("Error registering level:", err)
+	}
+	defer unregisterLevel(NOTICE)
+
+	level, err := ParseLevel("notice")
+	if err != nil {
+		t.Fatal("Error parsing level:", err)
+	}
+	logger, received := newChanLogger(2, level, NoLocation)
+	logger.Log(INFO, "info")
+	logger.Log(NOTICE, "notice")
+	logger.Log(WARNING, "warning")
+	if m := receive(t, received); m.Level != NOTICE || m.Message != "notice" {
+		t.Errorf("Expected notice, got %v", m)
+	}
+	if m := receive(t, received); m.Level != WARNING || m.Message != "warning" {
+		t.Errorf("Expected warning, got %v", m)
+	}
+
+	logger.SetMinLogLevel(WARNING)
+	logger.Log(NOTICE, "notice")
+	if len(received) != 0 {
+		t.Error("Message logged below the minloglevel")
+	}
+}
+
+func TestNewPackageLoggerMinLogLevel(t *testing.T) {
+	logger, received := newChanLogger(1, WARNING, NoLocation)
+
+	logger.Info("dropped")
+	logger.Warning("logged")
+	if m := receive(t, received); m.Message != "logged" {
+		t.Errorf("Expected logged, got %v", m)
+	}
+}
+
+func TestDebugAndTrace(t *testing.T) {
+	logger, received := newChanLogger(2, INFO, NoLocation)
+
+	var called bool = false
+	logger.Trace("trace")
+	logger.Tracef("trace %d", 1)
+	logger.Tracec(func() string { called = true; return "trace" })
+	logger.Debug("debug")
+	logger.Debugf("debug %d", 1)
+	logger.Debugc(func() string { called = true; return "debug" })
+	if len(received) != 0 {
+		t.Error("Message logged below the minloglevel")
+	}
+	if called {
+		t.Error("Closure evaluated even though no output produced")
+	}
+
+	logger.SetMinLogLevel(DEBUG)
+	logger.Tracec(func() string { called = true; return "trace" })
+	logger.Debugf("debug %d", 2)
+	if m := receive(t, received); m.Level != DEBUG || m.Message != "debug 2" {
+		t.Errorf("Expected debug 2, got %v", m)
+	}
+	if called {
+		t.Error("Closure evaluated even though no output produced")
+	}
+
+	logger.SetMinLogLevel(TRACE)
+	logger.Trace("trace ", 3)
+	logger.Debugc(func() string { return "debug 3" })
+	if m := receive(t, received); m.Level != TRACE || m.Message != "trace 3" {
+		t.Errorf("Expected trace 3, got %v", m)
+	}
+	if m := receive(t, received); m.Level != DEBUG || m.Message != "debug 3" {
+		t.Errorf("Expected debug 3, got %v", m)
+	}
+}
+
+func TestGlobalDebugAndTrace(t *testing.T) {
+	received := make(chanLogOuter, 2)
+	Global.AddLogOuter("chan", received)
+	defer Global.RemoveLogOuter("chan")
+	Global.SetMinLogLevel(INFO)
+	defer Global.SetMinLogLevel(defaultMinLogLevel)
+
+	var called bool = false
+	Tracef("trace %d", 1)
+	Debugc(func() string { called = true; return "debug" })
+	if len(received) != 0 || called {
+		t.Error("Message logged below the minloglevel")
+	}
+
+	Global.SetMinLogLevel(TRACE)
+	Trace("trace")
+	Debugf("debug %d", 2)
+	if m := receive(t, received); m.Level != TRACE || m.Message != "trace" {
+		t.Errorf("Expected trace, got %v", m)
+	}
+	if m := receive(t, received); m.Level != DEBUG || m.Message != "debug 2" {
+		t.Errorf("Expected debug 2, got %v", m)
+	}
+}
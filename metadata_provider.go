@@ -0,0 +1,161 @@
+package golog
+
+import (
+	"os"
+	"sort"
+	"sync"
+)
+
+// A MetadataProvider returns Fields to add to the Metadata of every
+// LogMessage, such as the version of the binary or the region it runs in.
+type MetadataProvider func() []Field
+
+type registeredProvider struct {
+	name     string
+	provider MetadataProvider
+	// The rendered Fields of a static provider, nil for a per message
+	// provider.
+	static map[string]string
+}
+
+var providerLock sync.RWMutex
+
+// Sorted by name, so that the result is deterministic if providers add the
+// same key.
+var metadataProviders []*registeredProvider
+
+// Registers the provider under the name, replacing any provider previously
+// registered with the name. The provider is evaluated once, now, which suits
+// values fixed for the life of the process. For example,
+//	golog.RegisterStaticMetadataProvider("build", func() []golog.Field {
+//		return []golog.Field{golog.String("version", version),
+//			golog.String("git_sha", gitSHA)}
+//	})
+func RegisterStaticMetadataProvider(name string, provider MetadataProvider) {
+	static := make(map[string]string)
+	addFields(static, provider())
+	registerMetadataProvider(&registeredProvider{name, provider, static})
+}
+
+// Registers the provider under the name, replacing any provider previously
+// registered with the name. The provider is evaluated for every LogMessage
+// that is output, so it should be cheap.
+func RegisterMetadataProvider(name string, provider MetadataProvider) {
+	registerMetadataProvider(&registeredProvider{name, provider, nil})
+}
+
+func registerMetadataProvider(p *registeredProvider) {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+
+	removeMetadataProvider(p.name)
+	i := sort.Search(len(metadataProviders), func(i int) bool {
+		return metadataProviders[i].name >= p.name
+	})
+	metadataProviders = append(metadataProviders, nil)
+	copy(metadataProviders[i+1:], metadataProviders[i:])
+	metadataProviders[i] = p
+}
+
+// Removes the provider registered under the name.
+func UnregisterMetadataProvider(name string) {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+
+	removeMetadataProvider(name)
+}
+
+// Must be called with the lock held.
+func removeMetadataProvider(name string) {
+	for i, p := range metadataProviders {
+		if p.name == name {
+			metadataProviders = append(metadataProviders[:i],
+				metadataProviders[i+1:]...)
+			return
+		}
+	}
+}
+
+var hostnameLock sync.RWMutex
+var hostname string
+var hostnameLoaded bool
+
+// Returns the hostname, or the empty string if it is unknown. Only asks the
+// operating system the first time and after RefreshStaticMetadata.
+func cachedHostname() string {
+	hostnameLock.RLock()
+	if hostnameLoaded {
+		defer hostnameLock.RUnlock()
+		return hostname
+	}
+	hostnameLock.RUnlock()
+
+	return refreshHostname()
+}
+
+func refreshHostname() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = ""
+	}
+
+	hostnameLock.Lock()
+	defer hostnameLock.Unlock()
+
+	hostname, hostnameLoaded = host, true
+	return host
+}
+
+// Recomputes the metadata that is computed once: the hostname and the Fields
+// of static providers. Useful if, for example, the hostname changes after the
+// process starts.
+func RefreshStaticMetadata() {
+	refreshHostname()
+
+	providerLock.Lock()
+	defer providerLock.Unlock()
+
+	for _, p := range metadataProviders {
+		if p.static != nil {
+			static := make(map[string]string)
+			addFields(static, p.provider())
+			p.static = static
+		}
+	}
+}
+
+// Returns a MetadataProvider that adds the value of the environment variable
+// under the key, if the variable is set. For example,
+//	golog.RegisterStaticMetadataProvider("region",
+//		golog.EnvMetadataProvider("region", "AWS_REGION"))
+func EnvMetadataProvider(key, variable string) MetadataProvider {
+	return func() []Field {
+		if value := os.Getenv(variable); value != "" {
+			return []Field{String(key, value)}
+		}
+		return nil
+	}
+}
+
+// Adds the Fields of every registered provider to the metadata, without
+// overwriting existing keys.
+func addProvidedMetadata(metadata map[string]string) {
+	providerLock.RLock()
+	defer providerLock.RUnlock()
+
+	for _, p := range metadataProviders {
+		if p.static != nil {
+			for key, value := range p.static {
+				if _, ok := metadata[key]; !ok {
+					metadata[key] = value
+				}
+			}
+			continue
+		}
+		for _, field := range p.provider() {
+			if _, ok := metadata[field.Key]; !ok {
+				metadata[field.Key] = field.Value()
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package golog
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMetadataProvider(t *testing.T) {
+	logger, received := newChanLogger(2, INFO, NoLocation)
+
+	calls := 0
+	RegisterStaticMetadataProvider("build", func() []Field {
+		calls++
+		return []Field{String("version", "1.2"), String("key", "static")}
+	})
+	defer UnregisterMetadataProvider("build")
+	RegisterMetadataProvider("request", func() []Field {
+		return []Field{Int("calls", calls)}
+	})
+	defer UnregisterMetadataProvider("request")
+
+	logger.Debug("filtered")
+	logger.Infow("first", "key", "call site")
+	logger.Info("second")
+
+	first, second := receive(t, received), receive(t, received)
+	if first.Metadata["version"] != "1.2" ||
+		first.Metadata["key"] != "call site" ||
+		first.Metadata["calls"] != "1" {
+		t.Errorf("Unexpected metadata %v", first.Metadata)
+	}
+	if second.Metadata["key"] != "static" || calls != 1 {
+		t.Errorf("Unexpected metadata %v", second.Metadata)
+	}
+
+	UnregisterMetadataProvider("build")
+	logger.Info("third")
+	if m := receive(t, received); m.Metadata["version"] != "" {
+		t.Errorf("Unregistered provider used, got %v", m.Metadata)
+	}
+}
+
+func BenchmarkHostnameUncached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		os.Hostname()
+	}
+}
+
+func BenchmarkHostnameCached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		cachedHostname()
+	}
+}
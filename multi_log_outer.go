@@ -4,17 +4,22 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"sync"
 )
 
 // A MultiLogOuter is a LogOuter with multiple keyed LogOuters. All functions
-// should be safe to call in a multi-threaded environment.
+// should be safe to call in a multi-threaded environment. The MultiLogOuters
+// returned by this package are also Reopeners, Flushers, and io.Closers that
+// propagate the call to each of their LogOuters.
 type MultiLogOuter interface {
 	LogOuter
-	// Add the LogOuter, associating it with the key.
+	// Add the LogOuter, associating it with the key. Any LogOuter
+	// previously associated with the key is removed.
 	AddLogOuter(key string, outer LogOuter)
-	// Remove the LogOuter associated with the key.
+	// Remove the LogOuter associated with the key, closing it if it is an
+	// io.Closer.
 	RemoveLogOuter(key string)
 }
 
@@ -59,7 +64,7 @@ func (l *multiLogOuterImpl) String() string {
 }
 
 func (l *multiLogOuterImpl) Set(name string) bool {
-	if outer, err := NewFileLogOuter(name); err != nil {
+	if outer, err := newFlagFileLogOuter(name); err != nil {
 		os.Stderr.WriteString(
 			fmt.Sprint("Error opening file for logging", name,
 				": ", err))
@@ -72,10 +77,34 @@ func (l *multiLogOuterImpl) Set(name string) bool {
 	panic("Code never reaches here, this mollifies the compiler.")
 }
 
+// Returns a LogOuter for a file provided via flag. Files are rendered with the
+// format selected by the golog.logformat flag, regardless of the order the
+// flags are provided. If no format is selected, terminals are colored. The
+// returned LogOuter is a Reopener.
+func newFlagFileLogOuter(name string) (LogOuter, error) {
+	file, err := openLogFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var formatter Formatter = defaultLogFormat
+	if useColor(file) {
+		formatter = &terminalFormatter{defaultLogFormat}
+	}
+	return &reopenableFileLogOuter{
+		filename:  name,
+		file:      file,
+		formatter: formatter,
+	}, nil
+}
+
 func (l *multiLogOuterImpl) AddLogOuter(key string, outer LogOuter) {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
+	if old, ok := l.outers[key]; ok && old != outer {
+		closeLogOuter(old)
+	}
 	l.outers[key] = outer
 }
 
@@ -83,7 +112,68 @@ func (l *multiLogOuterImpl) RemoveLogOuter(key string) {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
-	delete(l.outers, key)
+	if outer, ok := l.outers[key]; ok {
+		closeLogOuter(outer)
+		delete(l.outers, key)
+	}
+}
+
+// Closes the LogOuter if it is an io.Closer.
+func closeLogOuter(outer LogOuter) error {
+	if closer, ok := outer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Implements Flusher.Flush() by flushing every LogOuter that is a Flusher.
+// Returns the first error encountered, if any.
+func (l *multiLogOuterImpl) Flush() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var firstErr error
+	for _, outer := range l.outers {
+		if flusher, ok := outer.(Flusher); ok {
+			if err := flusher.Flush(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Implements io.Closer.Close() by removing and closing every LogOuter. Returns
+// the first error encountered, if any.
+func (l *multiLogOuterImpl) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var firstErr error
+	for key, outer := range l.outers {
+		if err := closeLogOuter(outer); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(l.outers, key)
+	}
+	return firstErr
+}
+
+// Implements Reopener.Reopen() by reopening every LogOuter that is a
+// Reopener. Returns the first error encountered, if any.
+func (l *multiLogOuterImpl) Reopen() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var firstErr error
+	for _, outer := range l.outers {
+		if reopener, ok := outer.(Reopener); ok {
+			if err := reopener.Reopen(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
 }
 
 func (l *multiLogOuterImpl) Output(m *LogMessage) {
@@ -97,11 +187,26 @@ func (l *multiLogOuterImpl) Output(m *LogMessage) {
 
 var defaultLogOuters MultiLogOuterFlag = NewMultiLogOuter()
 
+// Wraps the default MultiLogOuter so that it is shared rather than owned:
+// closing or removing it from another MultiLogOuter does not close it.
+type sharedLogOuter struct {
+	*multiLogOuterImpl
+}
+
+// Shadows the Close() method of the wrapped MultiLogOuter.
+func (s sharedLogOuter) Close() error {
+	return nil
+}
+
 // Create a new MultiLogOuter initialized with a mapping of "default" to the 
+// default MultiLogOuter. Closing the returned MultiLogOuter does not close the
 // default MultiLogOuter.
 func NewDefaultMultiLogOuter() MultiLogOuterFlag {
 	return &multiLogOuterImpl{
-		outers: map[string]LogOuter{"default": defaultLogOuters},
+		outers: map[string]LogOuter{
+			"default": sharedLogOuter{
+				defaultLogOuters.(*multiLogOuterImpl)},
+		},
 	}
 }
 
@@ -0,0 +1,69 @@
+package golog
+
+import (
+	"testing"
+)
+
+type closingLogOuter struct {
+	flushed, closed bool
+}
+
+func (o *closingLogOuter) Output(m *LogMessage) {}
+
+func (o *closingLogOuter) Flush() error {
+	o.flushed = true
+	return nil
+}
+
+func (o *closingLogOuter) Close() error {
+	o.closed = true
+	return nil
+}
+
+func TestRemoveLogOuterCloses(t *testing.T) {
+	outer := &closingLogOuter{}
+
+	multi := NewMultiLogOuter()
+	multi.AddLogOuter("closing", outer)
+	multi.RemoveLogOuter("closing")
+
+	if !outer.closed {
+		t.Error("Removed LogOuter not closed")
+	}
+}
+
+func TestFailNowFlushes(t *testing.T) {
+	outer := &closingLogOuter{}
+
+	multi := NewMultiLogOuter()
+	multi.AddLogOuter("closing", outer)
+
+	var called bool = false
+	logger := NewLogger(multi, INFO, func() { called = true })
+	logger.FailNow()
+
+	if !outer.flushed {
+		t.Error("LogOuter not flushed before failing")
+	}
+	if !called {
+		t.Error("Fail function not called!")
+	}
+}
+
+func TestCloseDoesNotCloseDefault(t *testing.T) {
+	outer := &closingLogOuter{}
+	defaultLogOuters.AddLogOuter("closing", outer)
+	defer defaultLogOuters.RemoveLogOuter("closing")
+
+	logger := NewDefaultPackageLogger()
+	if err := logger.Close(); err != nil {
+		t.Error("Error closing PackageLogger:", err)
+	}
+
+	if !outer.flushed {
+		t.Error("Default LogOuter not flushed")
+	}
+	if outer.closed {
+		t.Error("Default LogOuter closed by PackageLogger")
+	}
+}
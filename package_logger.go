@@ -1,14 +1,9 @@
 package golog
 
 import (
+	"context"
 	"fmt"
-)
-
-const (
-	INFO int = iota
-	WARNING
-	ERROR
-	FATAL
+	"io"
 )
 
 type StringLogger interface {
@@ -23,6 +18,77 @@ type StringLogger interface {
 	// Log the message at the level provided. Only evaluates the closure if
 	// the message will be logged.
 	Logc(int, func() string)
+	// Log the message at the level provided with the structured fields
+	// added to the Metadata. keysAndValues is a list of alternating keys
+	// and values, and may also contain Fields. For example,
+	//	Logw(INFO, "Request served", "user", id, golog.Int("status", 200))
+	// Values are only rendered if the message will be logged.
+	Logw(level int, msg string, keysAndValues ...interface{})
+	// Log the message at the level provided, formatting the message as if
+	// via a call to fmt.Sprint and adding the fields carried by the
+	// context (see ContextWith and RegisterContextExtractor) to the
+	// Metadata.
+	LogCtx(ctx context.Context, level int, msg ...interface{})
+	// Log the message at the TRACE level with the fields carried by the
+	// context (see LogCtx).
+	TraceCtx(ctx context.Context, msg ...interface{})
+	// Log the message at the DEBUG level with the fields carried by the
+	// context (see LogCtx).
+	DebugCtx(ctx context.Context, msg ...interface{})
+	// Log the message at the INFO level with the fields carried by the
+	// context (see LogCtx).
+	InfoCtx(ctx context.Context, msg ...interface{})
+	// Log the message at the WARNING level with the fields carried by the
+	// context (see LogCtx).
+	WarningCtx(ctx context.Context, msg ...interface{})
+	// Log the message at the ERROR level with the fields carried by the
+	// context (see LogCtx).
+	ErrorCtx(ctx context.Context, msg ...interface{})
+	// Log the message at the FATAL level with the fields carried by the
+	// context (see LogCtx). Afterwards,
+	// calls a LogOuter.FailNow().
+	FatalCtx(ctx context.Context, msg ...interface{})
+	// Log the message at the TRACE level with the structured fields added
+	// to the Metadata (see Logw).
+	Tracew(msg string, keysAndValues ...interface{})
+	// Log the message at the DEBUG level with the structured fields added
+	// to the Metadata (see Logw).
+	Debugw(msg string, keysAndValues ...interface{})
+	// Log the message at the INFO level with the structured fields added
+	// to the Metadata (see Logw).
+	Infow(msg string, keysAndValues ...interface{})
+	// Log the message at the WARNING level with the structured fields added
+	// to the Metadata (see Logw).
+	Warningw(msg string, keysAndValues ...interface{})
+	// Log the message at the ERROR level with the structured fields added
+	// to the Metadata (see Logw).
+	Errorw(msg string, keysAndValues ...interface{})
+	// Log the message at the FATAL level with the structured fields added
+	// to the Metadata (see Logw). Afterwards,
+	// calls a LogOuter.FailNow().
+	Fatalw(msg string, keysAndValues ...interface{})
+	// Log the message at the TRACE level, formatting the message as if via
+	// a call to fmt.Sprint and only rendering the string if the message
+	// will be logged.
+	Trace(msg ...interface{})
+	// Log the message at the TRACE level, formatting the message as if via
+	// a call to fmt.Sprintf and only rendering the string if the message
+	// will be logged.
+	Tracef(string, ...interface{})
+	// Log the message at the TRACE level, only evaluating the closure and 
+	// rendering the string if the message will be logged.
+	Tracec(func() string)
+	// Log the message at the DEBUG level, formatting the message as if via
+	// a call to fmt.Sprint and only rendering the string if the message
+	// will be logged.
+	Debug(msg ...interface{})
+	// Log the message at the DEBUG level, formatting the message as if via
+	// a call to fmt.Sprintf and only rendering the string if the message
+	// will be logged.
+	Debugf(string, ...interface{})
+	// Log the message at the DEBUG level, only evaluating the closure and 
+	// rendering the string if the message will be logged.
+	Debugc(func() string)
 	// Log the message at the INFO level, formatting the message as if via
 	// a call to fmt.Sprint and only rendering the string if the message
 	// will be logged.
@@ -77,6 +143,8 @@ type PackageLogger struct {
 	logger   LocationLogger
 	outer    MultiLogOuter
 	failFunc func()
+	// The PackageLogger this was derived from via With(), if any.
+	parent *PackageLogger
 }
 
 func NewPackageLogger(outer MultiLogOuter, minloglevel int,
@@ -86,7 +154,7 @@ func NewPackageLogger(outer MultiLogOuter, minloglevel int,
 	ret.logger = NewLocationLogger(
 		&loggerImpl{
 			outer,
-			defaultMinLogLevel,
+			minloglevel,
 			func() { ret.failFunc() },
 		},
 		metadataFunc)
@@ -103,9 +171,56 @@ func NewDefaultPackageLogger() *PackageLogger {
 		MakeMetadataFunc(DefaultMetadata))
 }
 
+// A LocationLogger that adds bound fields to every LogMessage.
+type boundLocationLogger struct {
+	LocationLogger
+	fields []Field
+}
+
+func (l *boundLocationLogger) LogDepth(level int, closure func() string, depth int) {
+	l.LocationLogger.LogDepthFields(level, closure, l.fields, depth+1)
+}
+
+func (l *boundLocationLogger) LogDepthFields(level int, closure func() string, fields []Field, depth int) {
+	// Fields provided at the call site take precedence over bound fields.
+	all := make([]Field, 0, len(l.fields)+len(fields))
+	all = append(all, l.fields...)
+	all = append(all, fields...)
+	l.LocationLogger.LogDepthFields(level, closure, all, depth+1)
+}
+
+func (l *boundLocationLogger) logDepthUnfiltered(level int, closure func() string, fields []Field, skip int) {
+	all := make([]Field, 0, len(l.fields)+len(fields))
+	all = append(all, l.fields...)
+	all = append(all, fields...)
+	logUnfiltered(l.LocationLogger, level, closure, all, skip+1)
+}
+
+// Returns a child PackageLogger that adds the fields to the Metadata of every
+// LogMessage it logs. keysAndValues is as in Logw. The child shares the
+// LogOuters, minloglevel, and test logging state of l, so closing the child
+// only flushes the LogOuters and l can still be used. For example,
+//	log := golog.Global.With("request_id", id)
+//	defer log.Close()
+//	log.Info("Handling request")
+func (l *PackageLogger) With(keysAndValues ...interface{}) *PackageLogger {
+	return &PackageLogger{
+		logger: &boundLocationLogger{l.logger,
+			makeFields(keysAndValues)},
+		outer:    l.outer,
+		failFunc: l.failFunc,
+		parent:   l,
+	}
+}
+
 // Associates TestController with a the "testing LogOuter and updates
 // l.FailNow() to call t.FailNow().
 func (l *PackageLogger) StartTestLogging(t TestController) {
+	if l.parent != nil {
+		// Our FailNow() calls the failFunc of the root.
+		l.parent.StartTestLogging(t)
+		return
+	}
 	l.outer.AddLogOuter("testing", NewTestLogOuter(t))
 	// TODO(awreece) Save old failFunc so we can restore it properly.
 	l.failFunc = func() { t.FailNow() }
@@ -113,6 +228,10 @@ func (l *PackageLogger) StartTestLogging(t TestController) {
 
 // Removes the testing logger and restores l.FailNow() to its previous state.
 func (l *PackageLogger) StopTestLogging() {
+	if l.parent != nil {
+		l.parent.StopTestLogging()
+		return
+	}
 	l.outer.RemoveLogOuter("testing")
 	// TODO(awreece) Restored to saved failFunc.
 	l.failFunc = ExitError
@@ -132,6 +251,36 @@ func printfClosure(format string, vals ...interface{}) func() string {
 	}
 }
 
+// Implement StringLogger.Trace().
+func (l *PackageLogger) Trace(msg ...interface{}) {
+	l.logger.LogDepth(TRACE, printClosure(msg...), 1)
+}
+
+// Implement StringLogger.Tracef().
+func (l *PackageLogger) Tracef(fmt string, vals ...interface{}) {
+	l.logger.LogDepth(TRACE, printfClosure(fmt, vals...), 1)
+}
+
+// Implement StringLogger.Tracec().
+func (l *PackageLogger) Tracec(closure func() string) {
+	l.logger.LogDepth(TRACE, closure, 1)
+}
+
+// Implement StringLogger.Debug().
+func (l *PackageLogger) Debug(msg ...interface{}) {
+	l.logger.LogDepth(DEBUG, printClosure(msg...), 1)
+}
+
+// Implement StringLogger.Debugf().
+func (l *PackageLogger) Debugf(fmt string, vals ...interface{}) {
+	l.logger.LogDepth(DEBUG, printfClosure(fmt, vals...), 1)
+}
+
+// Implement StringLogger.Debugc().
+func (l *PackageLogger) Debugc(closure func() string) {
+	l.logger.LogDepth(DEBUG, closure, 1)
+}
+
 // Implements StringLogger.Info().
 func (l *PackageLogger) Info(msg ...interface{}) {
 	l.logger.LogDepth(INFO, printClosure(msg...), 1)
@@ -210,6 +359,92 @@ func (l *PackageLogger) Logc(level int, closure func() string) {
 	l.logger.LogDepth(level, closure, 1)
 }
 
+// Implement StringLogger.Tracew().
+func (l *PackageLogger) Tracew(msg string, keysAndValues ...interface{}) {
+	l.logger.LogDepthFields(TRACE, printClosure(msg),
+		makeFields(keysAndValues), 1)
+}
+
+// Implement StringLogger.Debugw().
+func (l *PackageLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.logger.LogDepthFields(DEBUG, printClosure(msg),
+		makeFields(keysAndValues), 1)
+}
+
+// Implement StringLogger.Infow().
+func (l *PackageLogger) Infow(msg string, keysAndValues ...interface{}) {
+	l.logger.LogDepthFields(INFO, printClosure(msg),
+		makeFields(keysAndValues), 1)
+}
+
+// Implement StringLogger.Warningw().
+func (l *PackageLogger) Warningw(msg string, keysAndValues ...interface{}) {
+	l.logger.LogDepthFields(WARNING, printClosure(msg),
+		makeFields(keysAndValues), 1)
+}
+
+// Implement StringLogger.Errorw().
+func (l *PackageLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.logger.LogDepthFields(ERROR, printClosure(msg),
+		makeFields(keysAndValues), 1)
+}
+
+// Implement StringLogger.Fatalw().
+func (l *PackageLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	l.logger.LogDepthFields(FATAL, printClosure(msg),
+		makeFields(keysAndValues), 1)
+	l.logger.FailNow()
+}
+
+// Implement StringLogger.Logw().
+func (l *PackageLogger) Logw(level int, msg string, keysAndValues ...interface{}) {
+	l.logger.LogDepthFields(level, printClosure(msg),
+		makeFields(keysAndValues), 1)
+}
+
+// Implement StringLogger.TraceCtx().
+func (l *PackageLogger) TraceCtx(ctx context.Context, msg ...interface{}) {
+	l.logger.LogDepthFields(TRACE, printClosure(msg...),
+		contextFields(ctx), 1)
+}
+
+// Implement StringLogger.DebugCtx().
+func (l *PackageLogger) DebugCtx(ctx context.Context, msg ...interface{}) {
+	l.logger.LogDepthFields(DEBUG, printClosure(msg...),
+		contextFields(ctx), 1)
+}
+
+// Implement StringLogger.InfoCtx().
+func (l *PackageLogger) InfoCtx(ctx context.Context, msg ...interface{}) {
+	l.logger.LogDepthFields(INFO, printClosure(msg...),
+		contextFields(ctx), 1)
+}
+
+// Implement StringLogger.WarningCtx().
+func (l *PackageLogger) WarningCtx(ctx context.Context, msg ...interface{}) {
+	l.logger.LogDepthFields(WARNING, printClosure(msg...),
+		contextFields(ctx), 1)
+}
+
+// Implement StringLogger.ErrorCtx().
+func (l *PackageLogger) ErrorCtx(ctx context.Context, msg ...interface{}) {
+	l.logger.LogDepthFields(ERROR, printClosure(msg...),
+		contextFields(ctx), 1)
+}
+
+// Implement StringLogger.FatalCtx().
+func (l *PackageLogger) FatalCtx(ctx context.Context, msg ...interface{}) {
+	l.logger.LogDepthFields(FATAL, printClosure(msg...),
+		contextFields(ctx), 1)
+	l.logger.FailNow()
+}
+
+// Implement StringLogger.LogCtx().
+func (l *PackageLogger) LogCtx(ctx context.Context, level int, msg ...interface{}) {
+	l.logger.LogDepthFields(level, printClosure(msg...),
+		contextFields(ctx), 1)
+}
+
 // Export MutliLogOuter.AddLogOuter().
 func (l *PackageLogger) AddLogOuter(key string, outer LogOuter) {
 	l.outer.AddLogOuter(key, outer)
@@ -220,6 +455,32 @@ func (l *PackageLogger) RemoveLogOuter(key string) {
 	l.outer.RemoveLogOuter(key)
 }
 
+// Flushes every LogOuter that is a Flusher. Returns the first error
+// encountered, if any.
+func (l *PackageLogger) Flush() error {
+	if flusher, ok := l.outer.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// Flushes and then closes every LogOuter, for clean shutdown. The
+// PackageLogger must not be used after it is closed. A child returned by With
+// shares the LogOuters of its parent, so closing it only flushes them. Returns
+// the first error encountered, if any.
+func (l *PackageLogger) Close() error {
+	err := l.Flush()
+	if l.parent != nil {
+		return err
+	}
+	if closer, ok := l.outer.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
 // Export Logger.SetMinLogLevel(). Note - this will affect MinLogLevel of
 // underlying Logger.
 func (l *PackageLogger) SetMinLogLevel(level int) {
@@ -0,0 +1,46 @@
+package golog
+
+import (
+	"testing"
+)
+
+func TestWith(t *testing.T) {
+	parent, received := newChanLogger(1, INFO, MakeMetadataFunc(File))
+	child := parent.With("request", 1, "user", "a").With("user", "b")
+	child.Infow("hello", "request", 2)
+
+	m := receive(t, received)
+	if m.Metadata["request"] != "2" || m.Metadata["user"] != "b" {
+		t.Errorf("Expected request=2 and user=b, got %v", m.Metadata)
+	}
+	if m.Metadata["file"] != "package_logger_test.go" {
+		t.Errorf("Expected file=package_logger_test.go, got %v",
+			m.Metadata)
+	}
+
+	// The child shares the minloglevel of the parent.
+	parent.SetMinLogLevel(ERROR)
+	child.Info("dropped")
+	if len(received) != 0 {
+		t.Error("Child logged below the minloglevel of the parent")
+	}
+}
+
+func TestWithClose(t *testing.T) {
+	outer := &closingLogOuter{}
+	multi := NewMultiLogOuter()
+	multi.AddLogOuter("closing", outer)
+
+	parent := NewPackageLogger(multi, INFO, nil, NoLocation)
+	if err := parent.With("request", 1).Close(); err != nil {
+		t.Error("Error closing child:", err)
+	}
+	if !outer.flushed || outer.closed {
+		t.Errorf("Expected child to only flush, got %+v", outer)
+	}
+
+	parent.Close()
+	if !outer.closed {
+		t.Error("Parent did not close its LogOuters")
+	}
+}
@@ -0,0 +1,50 @@
+package golog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReopenableFileLogOuter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golog")
+	if err != nil {
+		t.Fatal("Error creating temp dir:", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.log")
+	formatter := FormatterFunc(func(m *LogMessage) []byte {
+		return []byte(m.Message)
+	})
+	outer, err := NewReopenableFileLogOuterWithFormatter(filename, formatter)
+	if err != nil {
+		t.Fatal("Error opening file:", err)
+	}
+
+	// Simulate logrotate moving the file.
+	outer.Output(&LogMessage{Message: "before"})
+	if err := os.Rename(filename, filename+".1"); err != nil {
+		t.Fatal("Error renaming file:", err)
+	}
+	outer.Output(&LogMessage{Message: "moved"})
+
+	multi := NewMultiLogOuter()
+	multi.AddLogOuter("file", outer)
+	if err := multi.(Reopener).Reopen(); err != nil {
+		t.Fatal("Error reopening file:", err)
+	}
+	outer.Output(&LogMessage{Message: "after"})
+
+	for name, expected := range map[string]string{
+		filename + ".1": "before\nmoved\n",
+		filename:        "after\n",
+	} {
+		if contents, err := ioutil.ReadFile(name); err != nil {
+			t.Error("Error reading file:", err)
+		} else if string(contents) != expected {
+			t.Errorf("Expected %q in %s, got %q", expected, name, contents)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package golog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rotated files are named {filename}.{timestamp}, optionally followed by .gz.
+// The timestamp is in UTC and sorts lexicographically in chronological order.
+const rotateTimeLayout = "2006-01-02T15-04-05.000000"
+
+// Options controlling when a rotating file LogOuter rolls over to a new file
+// and which old files it keeps. A zero field disables the associated
+// behavior.
+type RotateOptions struct {
+	// Roll over before a write would grow the file beyond this many bytes.
+	MaxSize int64
+	// Roll over once the file has been open for this long.
+	Interval time.Duration
+	// Keep at most this many rotated files, deleting the oldest first.
+	MaxBackups int
+	// Delete rotated files older than this.
+	MaxAge time.Duration
+	// Gzip rotated files in the background.
+	Compress bool
+	// The Formatter used to render each LogMessage. If nil, the
+	// DefaultFormatter is used.
+	Formatter Formatter
+}
+
+type rotatingLogOuter struct {
+	lock     sync.Mutex
+	filename string
+	options  RotateOptions
+	file     *os.File
+	size     int64
+	opened   time.Time
+
+	// Held while compressing and pruning rotated files, so that background
+	// cleanups don't race with each other.
+	cleanupLock sync.Mutex
+	// Tracks background cleanups so Close() can wait for them.
+	cleanups sync.WaitGroup
+}
+
+// Returns a LogOuter that appends to the file, rotating it according to the
+// options, or an error if the file cannot be opened. Rotated files are named
+// with the UTC time of the rotation, such as
+// "foo.log.2011-12-01T15-04-05.000000". For example,
+//	outer, err := NewRotatingFileLogOuter("foo.log", RotateOptions{
+//		MaxSize:    100 << 20,
+//		MaxBackups: 10,
+//		Compress:   true,
+//	})
+func NewRotatingFileLogOuter(filename string, options RotateOptions) (LogOuter, error) {
+	if options.Formatter == nil {
+		options.Formatter = DefaultFormatter
+	}
+
+	file, err := openLogFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &rotatingLogOuter{
+		filename: filename,
+		options:  options,
+		file:     file,
+		size:     info.Size(),
+		opened:   time.Now(),
+	}, nil
+}
+
+func (o *rotatingLogOuter) Output(m *LogMessage) {
+	// Make sure to insert a newline.
+	line := append(o.options.Formatter.Format(m), '\n')
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.shouldRotate(len(line)) {
+		// TODO(awreece) Report the error? If rotation fails we keep
+		// writing to the old file rather than dropping the message.
+		o.rotate()
+	}
+
+	// TODO(awreece) Handle short write?
+	n, _ := o.file.Write(line)
+	o.size += int64(n)
+}
+
+// Implements io.Closer.Close(). Waits for any background compression of rotated
+// files to complete.
+func (o *rotatingLogOuter) Close() error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	err := o.file.Close()
+	o.cleanups.Wait()
+	return err
+}
+
+// Returns true if the file should be rotated before writing n more bytes.
+// Must be called with the lock held.
+func (o *rotatingLogOuter) shouldRotate(n int) bool {
+	if o.options.MaxSize > 0 && o.size > 0 &&
+		o.size+int64(n) > o.options.MaxSize {
+		return true
+	}
+	if o.options.Interval > 0 &&
+		time.Now().Sub(o.opened) >= o.options.Interval {
+		return true
+	}
+	return false
+}
+
+// Renames the current file and opens a new one in its place. Must be called
+// with the lock held.
+func (o *rotatingLogOuter) rotate() error {
+	now := time.Now()
+	rotated := o.filename + "." + now.UTC().Format(rotateTimeLayout)
+
+	if err := os.Rename(o.filename, rotated); err != nil {
+		return err
+	}
+	file, err := openLogFile(o.filename)
+	if err != nil {
+		return err
+	}
+
+	o.file.Close()
+	o.file = file
+	o.size = 0
+	o.opened = now
+
+	if o.options.Compress {
+		// Compression can be slow, don't block logging on it.
+		o.cleanups.Add(1)
+		go func() {
+			defer o.cleanups.Done()
+			o.cleanup(rotated)
+		}()
+	} else {
+		o.cleanup(rotated)
+	}
+	return nil
+}
+
+// Compresses the newly rotated file if requested and prunes old files.
+func (o *rotatingLogOuter) cleanup(rotated string) {
+	o.cleanupLock.Lock()
+	defer o.cleanupLock.Unlock()
+
+	if o.options.Compress {
+		if err := gzipFile(rotated); err == nil {
+			os.Remove(rotated)
+		}
+	}
+	o.prune()
+}
+
+// Removes rotated files beyond MaxBackups or older than MaxAge.
+func (o *rotatingLogOuter) prune() {
+	if o.options.MaxBackups <= 0 && o.options.MaxAge <= 0 {
+		return
+	}
+
+	// Not filepath.Glob, which would treat any '[', '*' or '?' in the
+	// filename as a pattern.
+	dir, base := filepath.Split(o.filename)
+	names, err := readDirNames(dir)
+	if err != nil {
+		return
+	}
+
+	var backups []string
+	times := make(map[string]time.Time)
+	for _, name := range names {
+		if !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		stamp := name[len(base)+1:]
+		if strings.HasSuffix(stamp, ".gz") {
+			stamp = stamp[:len(stamp)-len(".gz")]
+		}
+		if t, err := time.Parse(rotateTimeLayout, stamp); err == nil {
+			backup := filepath.Join(dir, name)
+			backups = append(backups, backup)
+			times[backup] = t
+		}
+	}
+	sort.Strings(backups)
+
+	cutoff := time.Now().Add(-o.options.MaxAge)
+	for i, backup := range backups {
+		tooMany := o.options.MaxBackups > 0 &&
+			i < len(backups)-o.options.MaxBackups
+		tooOld := o.options.MaxAge > 0 && times[backup].Before(cutoff)
+		if tooMany || tooOld {
+			os.Remove(backup)
+		}
+	}
+}
+
+// Returns the names of the entries of the directory, or of the current
+// directory if dir is empty.
+func readDirNames(dir string) ([]string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return f.Readdirnames(-1)
+}
+
+// Writes a gzipped copy of the file to {filename}.gz.
+func gzipFile(filename string) error {
+	in, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(filename+".gz",
+		os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		gz.Close()
+		out.Close()
+		os.Remove(filename + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		os.Remove(filename + ".gz")
+		return err
+	}
+	return out.Close()
+}
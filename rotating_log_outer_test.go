@@ -0,0 +1,201 @@
+package golog
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Returns a temporary directory and a Formatter that only writes the message.
+func rotatingFixture(t *testing.T) (string, Formatter) {
+	dir, err := ioutil.TempDir("", "golog")
+	if err != nil {
+		t.Fatal("Error creating temp dir:", err)
+	}
+	return dir, FormatterFunc(func(m *LogMessage) []byte {
+		return []byte(m.Message)
+	})
+}
+
+func TestRotatingFileLogOuter(t *testing.T) {
+	dir, formatter := rotatingFixture(t)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.log")
+	outer, err := NewRotatingFileLogOuter(filename, RotateOptions{
+		MaxSize:    10,
+		MaxBackups: 2,
+		Formatter:  formatter,
+	})
+	if err != nil {
+		t.Fatal("Error opening rotating file:", err)
+	}
+
+	// Every message fills the file, so each one after the first rotates.
+	for _, message := range []string{"message1", "message2", "message3",
+		"message4"} {
+		outer.Output(&LogMessage{Message: message})
+	}
+
+	if contents, err := ioutil.ReadFile(filename); err != nil {
+		t.Error("Error reading file:", err)
+	} else if string(contents) != "message4\n" {
+		t.Errorf("Expected %q, got %q", "message4\n", contents)
+	}
+
+	if backups, _ := filepath.Glob(filename + ".*"); len(backups) != 2 {
+		t.Errorf("Expected 2 rotated files, got %v", backups)
+	}
+}
+
+func TestRotatingFileLogOuterPatternCharacters(t *testing.T) {
+	dir, formatter := rotatingFixture(t)
+	defer os.RemoveAll(dir)
+
+	// A directory whose name is not a valid glob pattern.
+	dir = filepath.Join(dir, "logs[*?")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal("Error creating dir:", err)
+	}
+	filename := filepath.Join(dir, "test[1].log")
+	outer, err := NewRotatingFileLogOuter(filename, RotateOptions{
+		MaxSize:    10,
+		MaxBackups: 1,
+		Formatter:  formatter,
+	})
+	if err != nil {
+		t.Fatal("Error opening rotating file:", err)
+	}
+
+	for _, message := range []string{"message1", "message2", "message3"} {
+		outer.Output(&LogMessage{Message: message})
+	}
+
+	if names, err := readDirNames(dir); err != nil {
+		t.Error("Error reading dir:", err)
+	} else if len(names) != 2 {
+		t.Errorf("Expected the file and 1 rotated file, got %v", names)
+	}
+}
+
+func TestRotatingFileLogOuterInterval(t *testing.T) {
+	dir, formatter := rotatingFixture(t)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.log")
+	outer, err := NewRotatingFileLogOuter(filename, RotateOptions{
+		Interval:  50 * time.Millisecond,
+		Formatter: formatter,
+	})
+	if err != nil {
+		t.Fatal("Error opening rotating file:", err)
+	}
+	defer closeLogOuter(outer)
+
+	outer.Output(&LogMessage{Message: "message1"})
+	outer.Output(&LogMessage{Message: "message2"})
+	if backups, _ := filepath.Glob(filename + ".*"); len(backups) != 0 {
+		t.Errorf("Rotated before the interval elapsed: %v", backups)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	outer.Output(&LogMessage{Message: "message3"})
+
+	if contents, err := ioutil.ReadFile(filename); err != nil {
+		t.Error("Error reading file:", err)
+	} else if string(contents) != "message3\n" {
+		t.Errorf("Expected %q, got %q", "message3\n", contents)
+	}
+	backups, _ := filepath.Glob(filename + ".*")
+	if len(backups) != 1 {
+		t.Fatalf("Expected 1 rotated file, got %v", backups)
+	}
+	if contents, _ := ioutil.ReadFile(backups[0]); string(contents) !=
+		"message1\nmessage2\n" {
+		t.Errorf("Expected %q, got %q", "message1\nmessage2\n", contents)
+	}
+}
+
+func TestRotatingFileLogOuterMaxAge(t *testing.T) {
+	dir, formatter := rotatingFixture(t)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.log")
+	old := filename + "." +
+		time.Now().Add(-2*time.Hour).UTC().Format(rotateTimeLayout)
+	recent := filename + "." +
+		time.Now().Add(-time.Minute).UTC().Format(rotateTimeLayout)
+	for _, backup := range []string{old, recent} {
+		if err := ioutil.WriteFile(backup, nil, 0666); err != nil {
+			t.Fatal("Error creating backup:", err)
+		}
+	}
+
+	outer, err := NewRotatingFileLogOuter(filename, RotateOptions{
+		MaxSize:   10,
+		MaxAge:    time.Hour,
+		Formatter: formatter,
+	})
+	if err != nil {
+		t.Fatal("Error opening rotating file:", err)
+	}
+	defer closeLogOuter(outer)
+
+	outer.Output(&LogMessage{Message: "message1"})
+	outer.Output(&LogMessage{Message: "message2"})
+
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Error("Expected backup older than MaxAge to be removed")
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Error("Expected recent backup to be kept:", err)
+	}
+	if backups, _ := filepath.Glob(filename + ".*"); len(backups) != 2 {
+		t.Errorf("Expected 2 rotated files, got %v", backups)
+	}
+}
+
+func TestRotatingFileLogOuterCompress(t *testing.T) {
+	dir, formatter := rotatingFixture(t)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.log")
+	outer, err := NewRotatingFileLogOuter(filename, RotateOptions{
+		MaxSize:    10,
+		MaxBackups: 1,
+		Compress:   true,
+		Formatter:  formatter,
+	})
+	if err != nil {
+		t.Fatal("Error opening rotating file:", err)
+	}
+
+	for _, message := range []string{"message1", "message2", "message3"} {
+		outer.Output(&LogMessage{Message: message})
+	}
+	// Waits for the background compression and pruning.
+	if err := closeLogOuter(outer); err != nil {
+		t.Error("Error closing:", err)
+	}
+
+	backups, _ := filepath.Glob(filename + ".*")
+	if len(backups) != 1 || filepath.Ext(backups[0]) != ".gz" {
+		t.Fatalf("Expected 1 compressed rotated file, got %v", backups)
+	}
+
+	file, err := os.Open(backups[0])
+	if err != nil {
+		t.Fatal("Error opening compressed file:", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal("Error reading compressed file:", err)
+	}
+	if contents, _ := ioutil.ReadAll(gz); string(contents) != "message2\n" {
+		t.Errorf("Expected %q, got %q", "message2\n", contents)
+	}
+}
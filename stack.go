@@ -0,0 +1,86 @@
+package golog
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"runtime"
+	"strconv"
+)
+
+// The key under which formatters render the Stack of a LogMessage.
+const stackKey = "stack"
+
+// The maximum number of frames recorded in the stack.
+const maxStackFrames = 64
+
+// The minimum level of messages for which the stack is recorded.
+type stackLevelFlag struct {
+	level int
+}
+
+var stackLevel = &stackLevelFlag{ERROR}
+
+func init() {
+	flag.Var(stackLevel, "golog.stack_minloglevel",
+		"Record the stack of the caller for messages at or above this "+
+			"level, if the metadata includes Stack")
+}
+
+func (s *stackLevelFlag) Set(val string) bool {
+	if level, err := ParseLevel(val); err == nil {
+		s.level = level
+		return true
+	} else {
+		fmt.Println("Error setting flag: ", err)
+	}
+
+	return false
+}
+
+func (s *stackLevelFlag) String() string {
+	return levelName(s.level)
+}
+
+// Returns the pcs of the stack of the caller skip frames up if the LogMessage
+// requests the stack and its level is at or above the minimum level, and nil
+// otherwise. Skip 0 refers to the function calling this function. The pcs are
+// symbolized by formatStack, so that the cost is only paid if the message is
+// output.
+func requestedStack(m *LogMessage, skip int) []uintptr {
+	if m.requests&Stack == 0 || m.Level < stackLevel.level {
+		return nil
+	}
+
+	pcs := make([]uintptr, maxStackFrames)
+	return pcs[:runtime.Callers(skip+2, pcs)]
+}
+
+// Returns the stack with the pcs, as one "{function} {file}:{line}" frame per
+// element.
+func formatStack(pcs []uintptr) []string {
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	stack := make([]string, 0, len(pcs))
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, frame.Function+" "+frame.File+":"+
+			strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// Render the Stack of the LogMessage to the buffer, if recorded, as an
+// indented block with one frame per line.
+func renderStack(buf *bytes.Buffer, m *LogMessage) {
+	for _, frame := range m.Stack {
+		buf.WriteString("\n\t")
+		buf.WriteString(frame)
+	}
+}
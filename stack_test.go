@@ -0,0 +1,31 @@
+package golog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStack(t *testing.T) {
+	logger, received := newChanLogger(2, INFO, MakeMetadataFunc(Stack))
+
+	logger.Warning("without stack")
+	if m := receive(t, received); len(m.Metadata) != 0 || m.Stack != nil {
+		t.Errorf("Expected no stack below ERROR, got %v", m.Stack)
+	}
+
+	logger.Error("with stack")
+	m := receive(t, received)
+	if len(m.Metadata) != 0 || len(m.Stack) == 0 ||
+		!strings.HasPrefix(m.Stack[0], testPackage+".TestStack ") ||
+		!strings.Contains(m.Stack[0], "stack_test.go:") {
+		t.Errorf("Expected stack of TestStack, got %q", m.Stack)
+	}
+
+	// A field named stack is just a field.
+	plain, received := newChanLogger(1, INFO, NoLocation)
+	plain.Errorw("field", "stack", "")
+	if m := receive(t, received); len(m.Metadata) != 1 ||
+		m.Metadata["stack"] != "" || m.Stack != nil {
+		t.Errorf("Expected only an empty stack field, got %q", m.Metadata)
+	}
+}
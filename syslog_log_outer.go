@@ -0,0 +1,486 @@
+package golog
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The framing of messages sent to syslog.
+type SyslogFormat int
+
+const (
+	// The syslog protocol described in RFC 5424, with Metadata sent as
+	// structured data.
+	RFC5424 SyslogFormat = iota
+	// The BSD syslog protocol described in RFC 3164, with Metadata
+	// omitted.
+	RFC3164
+)
+
+// Syslog facilities, as defined in RFC 5424.
+const (
+	FacilityKern int = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLpr
+	FacilityNews
+	FacilityUucp
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFtp
+	FacilityLocal0 int = iota + 4
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// The SD-ID of the structured data element holding Metadata. 32473 is the
+// private enterprise number reserved for documentation.
+const syslogSDID = "golog@32473"
+
+// The syslog severity of each level. Levels not present are sent with the
+// severity of the nearest level below them.
+var syslogSeverities = map[int]int{
+	TRACE:   7, // Debug
+	DEBUG:   7, // Debug
+	INFO:    6, // Informational
+	WARNING: 4, // Warning
+	ERROR:   3, // Error
+	FATAL:   2, // Critical
+}
+
+// Paths to try when connecting to the local syslog daemon.
+var localSyslogPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Options controlling the messages sent to syslog.
+type SyslogOptions struct {
+	Format SyslogFormat
+	// Processes other than the kernel may not log to FacilityKern, so
+	// zero means FacilityUser.
+	Facility int
+	// If empty, the base name of the binary is used.
+	AppName string
+	// If empty, the hostname in the Metadata is used if present, otherwise
+	// the hostname of this machine.
+	Hostname string
+	// Timeouts for connecting and for each write. Default to 10s.
+	DialTimeout  time.Duration
+	WriteTimeout time.Duration
+	// The delay before reconnecting after a failed attempt, doubled after
+	// each failed attempt up to MaxBackoff. LogMessages output in the
+	// meantime are dropped. Default to 100ms and 30s.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// A SyslogLogOuter is a LogOuter that sends LogMessages to syslog,
+// reconnecting as necessary.
+type SyslogLogOuter interface {
+	LogOuter
+	io.Closer
+	// Returns the number of LogMessages dropped because they could not be
+	// sent.
+	Dropped() uint64
+	// Returns the number of failed connection attempts and writes.
+	Errors() uint64
+}
+
+type syslogLogOuter struct {
+	// Accessed atomically, so first in the struct to ensure alignment.
+	dropped uint64
+	errors  uint64
+
+	lock    sync.Mutex
+	network string
+	raddr   string
+	conn    net.Conn
+	options SyslogOptions
+	pid     string
+	// True if SyslogOptions.Hostname was provided, in which case it takes
+	// precedence over the hostname in the Metadata.
+	hostnameSet bool
+	// The delay after the next failed connection attempt, and the time
+	// before which we don't try to connect.
+	backoff time.Duration
+	retryAt time.Time
+}
+
+// Returns a SyslogLogOuter that sends each LogMessage to syslog at the
+// address, or an error if the address cannot be reached. The network is "unixgram",
+// "unix", "udp", or "tcp". If both network and raddr are empty, connects to the
+// local syslog daemon (usually via /dev/log). For example,
+//	outer, err := NewSyslogLogOuter("udp", "loghost:514", SyslogOptions{
+//		Facility: FacilityLocal0,
+//	})
+func NewSyslogLogOuter(network, raddr string, options SyslogOptions) (SyslogLogOuter, error) {
+	if options.Facility == FacilityKern {
+		options.Facility = FacilityUser
+	}
+	if options.AppName == "" {
+		options.AppName = path.Base(os.Args[0])
+	}
+	if options.DialTimeout <= 0 {
+		options.DialTimeout = 10 * time.Second
+	}
+	if options.WriteTimeout <= 0 {
+		options.WriteTimeout = 10 * time.Second
+	}
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = 100 * time.Millisecond
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = 30 * time.Second
+	}
+	hostnameSet := options.Hostname != ""
+	if !hostnameSet {
+		if host, err := os.Hostname(); err == nil {
+			options.Hostname = host
+		}
+	}
+
+	o := &syslogLogOuter{
+		network:     network,
+		raddr:       raddr,
+		options:     options,
+		pid:         strconv.Itoa(os.Getpid()),
+		hostnameSet: hostnameSet,
+		backoff:     options.MinBackoff,
+	}
+	if err := o.connect(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// (Re)connects to syslog. Must be called with the lock held or before the
+// LogOuter is shared.
+func (o *syslogLogOuter) connect() error {
+	if o.conn != nil {
+		o.conn.Close()
+		o.conn = nil
+	}
+
+	if o.network != "" || o.raddr != "" {
+		conn, err := net.DialTimeout(o.network, o.raddr,
+			o.options.DialTimeout)
+		if err != nil {
+			return err
+		}
+		o.conn = conn
+		return nil
+	}
+
+	var err error
+	for _, p := range localSyslogPaths {
+		for _, network := range []string{"unixgram", "unix"} {
+			var conn net.Conn
+			conn, err = net.DialTimeout(network, p,
+				o.options.DialTimeout)
+			if err == nil {
+				o.conn = conn
+				o.network = network
+				o.raddr = p
+				return nil
+			}
+		}
+	}
+	return err
+}
+
+// Returns the syslog severity of the level.
+func syslogSeverity(level int) int {
+	if severity, ok := syslogSeverities[level]; ok {
+		return severity
+	}
+
+	// Use the severity of the nearest known level below.
+	best, severity := 0, 7
+	found := false
+	for known, s := range syslogSeverities {
+		if known < level && (!found || known > best) {
+			best, severity, found = known, s, true
+		}
+	}
+	return severity
+}
+
+// Writes the string, or "-" if it is empty, truncated to n bytes and with
+// characters not allowed in a syslog header replaced with underscores.
+func writeSyslogHeaderField(buf *bytes.Buffer, s string, n int) {
+	if s == "" {
+		buf.WriteString("-")
+		return
+	}
+	if len(s) > n {
+		s = s[:n]
+	}
+	buf.WriteString(strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s))
+}
+
+// Writes the Metadata as a structured data element, or "-" if there is none.
+func writeSyslogStructuredData(buf *bytes.Buffer, metadata map[string]string) {
+	if len(metadata) == 0 {
+		buf.WriteString("-")
+		return
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for key, _ := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf.WriteString("[" + syslogSDID)
+	for _, key := range keys {
+		buf.WriteString(" ")
+		// PARAM-NAME is limited to 32 printable characters other
+		// than '=', ' ', ']', and '"'.
+		name := key
+		if len(name) > 32 {
+			name = name[:32]
+		}
+		buf.WriteString(strings.Map(func(r rune) rune {
+			if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+				return '_'
+			}
+			return r
+		}, name))
+		buf.WriteString(`="`)
+		for _, r := range metadata[key] {
+			if r == '"' || r == '\\' || r == ']' {
+				buf.WriteRune('\\')
+			}
+			buf.WriteRune(r)
+		}
+		buf.WriteString(`"`)
+	}
+	buf.WriteString("]")
+}
+
+// Renders the LogMessage in the configured format, without any transport
+// framing.
+func (o *syslogLogOuter) format(m *LogMessage) []byte {
+	var buf bytes.Buffer
+
+	priority := o.options.Facility*8 + syslogSeverity(m.Level)
+	hostname := o.options.Hostname
+	if host, ok := m.Metadata["hostname"]; ok && !o.hostnameSet {
+		hostname = host
+	}
+
+	switch o.options.Format {
+	case RFC3164:
+		fmt.Fprintf(&buf, "<%d>", priority)
+		buf.WriteString(m.Nanoseconds.Format(time.Stamp))
+		buf.WriteString(" ")
+		writeSyslogHeaderField(&buf, hostname, 255)
+		buf.WriteString(" ")
+		writeSyslogHeaderField(&buf, o.options.AppName, 32)
+		buf.WriteString("[" + o.pid + "]: ")
+		buf.WriteString(m.Message)
+	default:
+		fmt.Fprintf(&buf, "<%d>1 ", priority)
+		buf.WriteString(m.Nanoseconds.Format("2006-01-02T15:04:05.000000Z07:00"))
+		buf.WriteString(" ")
+		writeSyslogHeaderField(&buf, hostname, 255)
+		buf.WriteString(" ")
+		writeSyslogHeaderField(&buf, o.options.AppName, 48)
+		buf.WriteString(" ")
+		writeSyslogHeaderField(&buf, o.pid, 128)
+		// We don't use MSGID.
+		buf.WriteString(" - ")
+		writeSyslogStructuredData(&buf, m.Metadata)
+		buf.WriteString(" ")
+		buf.WriteString(m.Message)
+	}
+	return buf.Bytes()
+}
+
+// Adds transport framing to the message. Stream transports use octet
+// counting (RFC 6587) for RFC 5424 and a trailing newline for RFC 3164. Must
+// be called with the lock held.
+func (o *syslogLogOuter) frame(msg []byte) []byte {
+	switch o.network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		if o.options.Format == RFC3164 {
+			return append(msg, '\n')
+		}
+		return append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	return msg
+}
+
+func (o *syslogLogOuter) Output(m *LogMessage) {
+	msg := o.format(m)
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	// The network may change if we reconnect to the local daemon, so frame
+	// with the lock held.
+	msg = o.frame(msg)
+
+	if o.conn != nil {
+		if o.write(msg) {
+			return
+		}
+		// The syslog daemon may have restarted, reconnect right away.
+		o.retryAt = time.Time{}
+	}
+
+	if time.Now().Before(o.retryAt) {
+		atomic.AddUint64(&o.dropped, 1)
+		return
+	}
+	if err := o.connect(); err != nil {
+		atomic.AddUint64(&o.errors, 1)
+		atomic.AddUint64(&o.dropped, 1)
+		o.retryAt = time.Now().Add(o.backoff)
+		if o.backoff *= 2; o.backoff > o.options.MaxBackoff {
+			o.backoff = o.options.MaxBackoff
+		}
+		return
+	}
+	o.backoff = o.options.MinBackoff
+	if !o.write(msg) {
+		atomic.AddUint64(&o.dropped, 1)
+	}
+}
+
+// Writes the message to the connection, closing it if the write fails.
+// Returns whether the write succeeded. Must be called with the lock held.
+func (o *syslogLogOuter) write(msg []byte) bool {
+	o.conn.SetWriteDeadline(time.Now().Add(o.options.WriteTimeout))
+	if _, err := o.conn.Write(msg); err != nil {
+		atomic.AddUint64(&o.errors, 1)
+		o.conn.Close()
+		o.conn = nil
+		return false
+	}
+	return true
+}
+
+// Implements io.Closer.Close().
+func (o *syslogLogOuter) Close() error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.conn == nil {
+		return nil
+	}
+	err := o.conn.Close()
+	o.conn = nil
+	return err
+}
+
+// Implements SyslogLogOuter.Dropped().
+func (o *syslogLogOuter) Dropped() uint64 {
+	return atomic.LoadUint64(&o.dropped)
+}
+
+// Implements SyslogLogOuter.Errors().
+func (o *syslogLogOuter) Errors() uint64 {
+	return atomic.LoadUint64(&o.errors)
+}
+
+// Returns a syslog LogOuter described by the URL, for use by flags. The URL is
+// one of "local", "unixgram:///dev/log", "udp://host:514", or
+// "tcp://host:514", optionally followed by the query parameters format
+// (rfc5424 or rfc3164) and facility (a number). For example,
+//	udp://loghost:514?format=rfc3164&facility=16
+func newSyslogLogOuterFromURL(rawurl string) (LogOuter, error) {
+	var options SyslogOptions
+	if rawurl == "local" {
+		return NewSyslogLogOuter("", "", options)
+	}
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	switch strings.ToLower(query.Get("format")) {
+	case "", "rfc5424":
+		options.Format = RFC5424
+	case "rfc3164":
+		options.Format = RFC3164
+	default:
+		return nil, fmt.Errorf("unknown syslog format %q", query.Get("format"))
+	}
+	if facility := query.Get("facility"); facility != "" {
+		if options.Facility, err = strconv.Atoi(facility); err != nil {
+			return nil, err
+		}
+	}
+
+	switch u.Scheme {
+	case "unix", "unixgram":
+		return NewSyslogLogOuter(u.Scheme, u.Path, options)
+	case "udp", "tcp":
+		return NewSyslogLogOuter(u.Scheme, u.Host, options)
+	}
+	return nil, fmt.Errorf("unknown syslog network %q", u.Scheme)
+}
+
+type syslogFlag struct {
+	lock sync.Mutex
+	urls []string
+}
+
+func (f *syslogFlag) Set(val string) bool {
+	if outer, err := newSyslogLogOuterFromURL(val); err != nil {
+		os.Stderr.WriteString(
+			fmt.Sprint("Error connecting to syslog ", val, ": ", err,
+				"\n"))
+		return false
+	} else {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		f.urls = append(f.urls, val)
+		defaultLogOuters.AddLogOuter("syslog:"+val, outer)
+		return true
+	}
+
+	panic("Code never reaches here, this mollifies the compiler.")
+}
+
+func (f *syslogFlag) String() string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return "\"" + strings.Join(f.urls, ",") + "\""
+}
+
+func init() {
+	flag.Var(&syslogFlag{}, "golog.syslog",
+		"Log to syslog - one of local, unixgram:///dev/log, "+
+			"udp://host:port, or tcp://host:port, optionally "+
+			"followed by ?format=rfc3164&facility=16. Can be "+
+			"provided multiple times")
+}
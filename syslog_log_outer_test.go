@@ -0,0 +1,134 @@
+package golog
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSyslogLogOuterRFC5424(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Error listening:", err)
+	}
+	defer conn.Close()
+
+	outer, err := NewSyslogLogOuter("udp", conn.LocalAddr().String(),
+		SyslogOptions{
+			Facility: FacilityLocal0,
+			AppName:  "app",
+			Hostname: "host",
+		})
+	if err != nil {
+		t.Fatal("Error connecting:", err)
+	}
+	defer closeLogOuter(outer)
+
+	outer.Output(&LogMessage{
+		Level:       ERROR,
+		Nanoseconds: time.Date(2011, 10, 18, 15, 4, 5, 6000, time.UTC),
+		Message:     "hello",
+		Metadata:    map[string]string{"file": "foo.go", "line": "12"},
+	})
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal("Error reading:", err)
+	}
+
+	expected := "<131>1 2011-10-18T15:04:05.000006Z host app " +
+		outer.(*syslogLogOuter).pid +
+		` - [golog@32473 file="foo.go" line="12"] hello`
+	if actual := string(buf[:n]); actual != expected {
+		t.Errorf("Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestSyslogLogOuterRFC3164(t *testing.T) {
+	o := &syslogLogOuter{
+		network: "tcp",
+		options: SyslogOptions{
+			Format:   RFC3164,
+			Facility: FacilityUser,
+			AppName:  "app",
+			Hostname: "host",
+		},
+		pid: "42",
+	}
+
+	msg := o.frame(o.format(&LogMessage{
+		Level:       WARNING,
+		Nanoseconds: time.Date(2011, 10, 8, 15, 4, 5, 0, time.UTC),
+		Message:     "hello",
+	}))
+
+	expected := "<12>Oct  8 15:04:05 host app[42]: hello\n"
+	if string(msg) != expected {
+		t.Errorf("Expected %q, got %q", expected, msg)
+	}
+}
+
+func TestSyslogLogOuterHostname(t *testing.T) {
+	o := &syslogLogOuter{
+		network: "tcp",
+		options: SyslogOptions{
+			Format:   RFC3164,
+			Facility: FacilityUser,
+			AppName:  "app",
+			Hostname: "machine",
+		},
+		pid: "42",
+	}
+	message := &LogMessage{
+		Level:       WARNING,
+		Nanoseconds: time.Date(2011, 10, 8, 15, 4, 5, 0, time.UTC),
+		Message:     "hello",
+		Metadata:    map[string]string{"hostname": "metadata"},
+	}
+
+	// The hostname of this machine is only a default.
+	expected := "<12>Oct  8 15:04:05 metadata app[42]: hello\n"
+	if msg := o.frame(o.format(message)); string(msg) != expected {
+		t.Errorf("Expected %q, got %q", expected, msg)
+	}
+
+	o.hostnameSet = true
+	expected = "<12>Oct  8 15:04:05 machine app[42]: hello\n"
+	if msg := o.frame(o.format(message)); string(msg) != expected {
+		t.Errorf("Expected %q, got %q", expected, msg)
+	}
+}
+
+func TestSyslogLogOuterBackoff(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Error listening:", err)
+	}
+
+	outer, err := NewSyslogLogOuter("tcp", listener.Addr().String(),
+		SyslogOptions{MinBackoff: time.Hour})
+	if err != nil {
+		t.Fatal("Error connecting:", err)
+	}
+	defer outer.Close()
+
+	// The syslog daemon goes away.
+	listener.Close()
+	outer.(*syslogLogOuter).conn.Close()
+
+	// The write fails, and so does connecting again.
+	outer.Output(&LogMessage{Level: ERROR, Message: "first"})
+	if outer.Dropped() != 1 || outer.Errors() != 2 {
+		t.Errorf("Expected 1 dropped and 2 errors, got %d and %d",
+			outer.Dropped(), outer.Errors())
+	}
+
+	// We don't try to connect again until the backoff expires.
+	outer.Output(&LogMessage{Level: ERROR, Message: "second"})
+	if outer.Dropped() != 2 || outer.Errors() != 2 {
+		t.Errorf("Expected 2 dropped and 2 errors, got %d and %d",
+			outer.Dropped(), outer.Errors())
+	}
+}
@@ -0,0 +1,305 @@
+package golog
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// How LogMessages are delimited on a stream. Every LogMessage is encoded as
+// JSON, as by NewUDPLogOuter.
+type Framing int
+
+const (
+	// Each LogMessage is followed by a newline.
+	FramingNewline Framing = iota
+	// Each LogMessage is preceded by its length as a 4 byte big endian
+	// integer.
+	FramingLengthPrefix
+)
+
+// Options controlling a TCP LogOuter. A zero field uses the default.
+type TCPOptions struct {
+	Framing Framing
+	// If not nil, the connection uses TLS with this config.
+	TLSConfig *tls.Config
+	// The maximum number of LogMessages buffered while the collector is
+	// unreachable. Once full, the oldest LogMessages are dropped. Defaults
+	// to 1024.
+	SpillSize int
+	// The delay before the first reconnection attempt, doubled after each
+	// failed attempt up to MaxBackoff. Default to 100ms and 30s.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Timeouts for connecting and for each write. Default to 10s.
+	DialTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+// A TCPLogOuter is a LogOuter that streams LogMessages to a collector from a
+// background goroutine, reconnecting as necessary. Flush waits until every
+// buffered LogMessage is sent, or returns an error if the collector is
+// unreachable. Close sends what it can, connecting once more if there is no
+// connection, and then closes the connection.
+type TCPLogOuter interface {
+	LogOuter
+	Flusher
+	io.Closer
+	// Returns the number of LogMessages dropped because the spill buffer
+	// was full, they could not be encoded, or they were output after
+	// Close.
+	Dropped() uint64
+	// Returns the number of failed connection attempts and writes.
+	Errors() uint64
+}
+
+type tcpLogOuter struct {
+	// Accessed atomically, so first in the struct to ensure alignment.
+	dropped uint64
+	errors  uint64
+
+	raddr   string
+	options TCPOptions
+	// Closed when Close is called, to interrupt backoff.
+	closing chan bool
+	// Closed when the background goroutine exits.
+	exited chan bool
+
+	// Protects the fields below. Signals cond whenever they change.
+	lock  sync.Mutex
+	cond  *sync.Cond
+	queue [][]byte
+	// The number of frames ever removed from the front of the queue, so
+	// that run can tell whether the frame it sent is still first.
+	dequeued  uint64
+	connected bool
+	closed    bool
+}
+
+// Returns a TCPLogOuter that sends LogMessages to the address, or an error if
+// the address cannot be resolved. The connection is established in the
+// background. For example,
+//	outer, err := NewTCPLogOuter("collector:5140", TCPOptions{
+//		Framing:   FramingLengthPrefix,
+//		TLSConfig: &tls.Config{},
+//	})
+func NewTCPLogOuter(raddr string, options TCPOptions) (TCPLogOuter, error) {
+	if _, err := net.ResolveTCPAddr("tcp", raddr); err != nil {
+		return nil, err
+	}
+
+	if options.SpillSize <= 0 {
+		options.SpillSize = 1024
+	}
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = 100 * time.Millisecond
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = 30 * time.Second
+	}
+	if options.DialTimeout <= 0 {
+		options.DialTimeout = 10 * time.Second
+	}
+	if options.WriteTimeout <= 0 {
+		options.WriteTimeout = 10 * time.Second
+	}
+
+	o := &tcpLogOuter{
+		raddr:   raddr,
+		options: options,
+		closing: make(chan bool),
+		exited:  make(chan bool),
+		// Optimistically assume we can connect until we fail.
+		connected: true,
+	}
+	o.cond = sync.NewCond(&o.lock)
+
+	go o.run()
+	return o, nil
+}
+
+// Encodes the LogMessage as JSON with the framing.
+func encodeFrame(m *LogMessage, framing Framing) ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	if framing == FramingLengthPrefix {
+		frame := make([]byte, 4+len(data))
+		binary.BigEndian.PutUint32(frame, uint32(len(data)))
+		copy(frame[4:], data)
+		return frame, nil
+	}
+	return append(data, '\n'), nil
+}
+
+func (o *tcpLogOuter) Output(m *LogMessage) {
+	frame, err := encodeFrame(m, o.options.Framing)
+	if err != nil {
+		atomic.AddUint64(&o.dropped, 1)
+		return
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.closed {
+		atomic.AddUint64(&o.dropped, 1)
+		return
+	}
+	if len(o.queue) >= o.options.SpillSize {
+		o.queue = o.queue[1:]
+		o.dequeued++
+		atomic.AddUint64(&o.dropped, 1)
+	}
+	o.queue = append(o.queue, frame)
+	o.cond.Broadcast()
+}
+
+// Connects to the collector.
+func (o *tcpLogOuter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: o.options.DialTimeout}
+	if o.options.TLSConfig != nil {
+		return tls.DialWithDialer(dialer, "tcp", o.raddr,
+			o.options.TLSConfig)
+	}
+	return dialer.Dial("tcp", o.raddr)
+}
+
+// Records whether we are connected. Must be called with the lock held.
+func (o *tcpLogOuter) setConnected(connected bool) {
+	if o.connected != connected {
+		o.connected = connected
+		o.cond.Broadcast()
+	}
+}
+
+// Sends queued frames until closed, reconnecting as necessary.
+func (o *tcpLogOuter) run() {
+	var conn net.Conn
+	backoff := o.options.MinBackoff
+	// Set once we have connected after Close, so we only try once.
+	redialed := false
+
+	for {
+		o.lock.Lock()
+		for len(o.queue) == 0 && !o.closed {
+			o.cond.Wait()
+		}
+		closed := o.closed
+		if len(o.queue) == 0 || (closed && conn == nil && redialed) {
+			// We are closed, and either sent everything or can't.
+			atomic.AddUint64(&o.dropped, uint64(len(o.queue)))
+			o.queue = nil
+			o.lock.Unlock()
+			break
+		}
+		frame, position := o.queue[0], o.dequeued
+		o.lock.Unlock()
+
+		if conn == nil {
+			// After Close, make one last attempt to connect and send
+			// what we can, bounded by the dial and write timeouts.
+			redialed = closed
+
+			var err error
+			if conn, err = o.dial(); err != nil {
+				atomic.AddUint64(&o.errors, 1)
+				o.lock.Lock()
+				o.setConnected(false)
+				o.lock.Unlock()
+				if closed {
+					continue
+				}
+
+				select {
+				case <-time.After(backoff):
+				case <-o.closing:
+				}
+				if backoff *= 2; backoff > o.options.MaxBackoff {
+					backoff = o.options.MaxBackoff
+				}
+				continue
+			}
+			backoff = o.options.MinBackoff
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(o.options.WriteTimeout))
+		if _, err := conn.Write(frame); err != nil {
+			// Resend the frame on a new connection.
+			atomic.AddUint64(&o.errors, 1)
+			conn.Close()
+			conn = nil
+			o.lock.Lock()
+			o.setConnected(false)
+			o.lock.Unlock()
+			continue
+		}
+
+		o.lock.Lock()
+		o.setConnected(true)
+		// The frame may have been dropped from the queue while we were
+		// sending it, in which case there is nothing to remove.
+		if o.dequeued == position {
+			o.queue = o.queue[1:]
+			o.dequeued++
+		}
+		if len(o.queue) == 0 {
+			o.cond.Broadcast()
+		}
+		o.lock.Unlock()
+	}
+
+	if conn != nil {
+		conn.Close()
+	}
+	close(o.exited)
+}
+
+// Implements Flusher.Flush().
+func (o *tcpLogOuter) Flush() error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	for len(o.queue) > 0 && o.connected && !o.closed {
+		o.cond.Wait()
+	}
+	if len(o.queue) > 0 {
+		return fmt.Errorf("unable to send to %s, %d messages buffered",
+			o.raddr, len(o.queue))
+	}
+	return nil
+}
+
+// Implements io.Closer.Close().
+func (o *tcpLogOuter) Close() error {
+	o.lock.Lock()
+	if o.closed {
+		o.lock.Unlock()
+		return nil
+	}
+	o.closed = true
+	close(o.closing)
+	o.cond.Broadcast()
+	o.lock.Unlock()
+
+	<-o.exited
+	return nil
+}
+
+// Implements TCPLogOuter.Dropped().
+func (o *tcpLogOuter) Dropped() uint64 {
+	return atomic.LoadUint64(&o.dropped)
+}
+
+// Implements TCPLogOuter.Errors().
+func (o *tcpLogOuter) Errors() uint64 {
+	return atomic.LoadUint64(&o.errors)
+}
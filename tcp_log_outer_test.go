@@ -0,0 +1,164 @@
+package golog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+// Accepts connections on the listener and sends every line received.
+func acceptLines(listener net.Listener, lines chan []byte) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			reader := bufio.NewReader(conn)
+			for {
+				line, err := reader.ReadBytes('\n')
+				if err != nil {
+					return
+				}
+				lines <- line
+			}
+		}()
+	}
+}
+
+func TestTCPLogOuterClose(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Error listening:", err)
+	}
+	defer listener.Close()
+	lines := make(chan []byte, 10)
+	go acceptLines(listener, lines)
+
+	// Close may be called before the background goroutine ever connects,
+	// the message must still be sent.
+	for i := 0; i < 10; i++ {
+		outer, err := NewTCPLogOuter(listener.Addr().String(),
+			TCPOptions{})
+		if err != nil {
+			t.Fatal("Error creating LogOuter:", err)
+		}
+		outer.Output(&LogMessage{Message: "closing"})
+		outer.Close()
+
+		if outer.Dropped() != 0 {
+			t.Fatalf("Expected no dropped messages, got %d",
+				outer.Dropped())
+		}
+		select {
+		case line := <-lines:
+			var m LogMessage
+			if err := json.Unmarshal(line, &m); err != nil {
+				t.Error("Error decoding:", err)
+			} else if m.Message != "closing" {
+				t.Errorf("Expected closing, got %v", m)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for message")
+		}
+	}
+}
+
+func TestTCPLogOuterCloseUnreachable(t *testing.T) {
+	// Find an address with nothing listening.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Error listening:", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	outer, err := NewTCPLogOuter(addr, TCPOptions{MinBackoff: time.Hour})
+	if err != nil {
+		t.Fatal("Error creating LogOuter:", err)
+	}
+	outer.Output(&LogMessage{Message: "lost"})
+	outer.Close()
+
+	if outer.Dropped() != 1 {
+		t.Errorf("Expected 1 dropped message, got %d", outer.Dropped())
+	}
+}
+
+func TestTCPLogOuter(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Error listening:", err)
+	}
+	defer listener.Close()
+
+	outer, err := NewTCPLogOuter(listener.Addr().String(), TCPOptions{})
+	if err != nil {
+		t.Fatal("Error creating LogOuter:", err)
+	}
+	defer outer.Close()
+
+	outer.Output(&LogMessage{Level: ERROR, Message: "first"})
+
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal("Error accepting:", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+
+	if line, err := reader.ReadBytes('\n'); err != nil {
+		t.Fatal("Error reading:", err)
+	} else {
+		var m LogMessage
+		if err := json.Unmarshal(line, &m); err != nil {
+			t.Error("Error decoding:", err)
+		} else if m.Message != "first" || m.Level != ERROR {
+			t.Errorf("Expected first at ERROR, got %v", m)
+		}
+	}
+
+	// Reset the connection, so that the next write fails rather than
+	// succeeding locally and being lost. The frame that failed is resent
+	// on a new connection, followed by the rest in order.
+	conn.(*net.TCPConn).SetLinger(0)
+	conn.Close()
+
+	const count = 10
+	for i := 0; i < count; i++ {
+		outer.Output(&LogMessage{Message: fmt.Sprint(i)})
+	}
+
+	conn, err = listener.Accept()
+	if err != nil {
+		t.Fatal("Error accepting:", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	reader = bufio.NewReader(conn)
+
+	for i := 0; i < count; i++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			t.Fatal("Error reading:", err)
+		}
+		var m LogMessage
+		if err := json.Unmarshal(line, &m); err != nil {
+			t.Error("Error decoding:", err)
+		} else if m.Message != fmt.Sprint(i) {
+			t.Errorf("Expected %d, got %v", i, m)
+		}
+	}
+
+	if err := outer.Flush(); err != nil {
+		t.Error("Error flushing:", err)
+	}
+	if outer.Dropped() != 0 || outer.Errors() != 1 {
+		t.Errorf("Expected 0 dropped and 1 error, got %d and %d",
+			outer.Dropped(), outer.Errors())
+	}
+}
@@ -0,0 +1,147 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const defaultTemplateTimeLayout = "15:04:05.000000"
+
+// A single piece of a parsed template: either literal text or a field.
+type templateSegment struct {
+	literal string
+	field   string
+	// Only used for the time field.
+	layout string
+}
+
+type templateFormatter struct {
+	segments []templateSegment
+	// The Metadata keys referenced by the template, which are not
+	// rendered by {fields}.
+	referenced map[string]bool
+}
+
+// Returns a Formatter that renders each LogMessage according to the template,
+// or an error if the template cannot be parsed. Fields in the template are
+// surrounded by braces and are replaced as follows:
+//	{level}       the name of the level, e.g. ERROR
+//	{levelnum}    the number of the level, e.g. 200
+//	{time}        the time formatted as 15:04:05.000000
+//	{time:layout} the time formatted with the layout, as in time.Format
+//	{message}     the message
+//	{key}         the value of key in the Metadata, e.g. {file} or {line}
+//	{fields}      every key in the Metadata not otherwise referenced in the
+//	              template, each as " key=value", in sorted order
+// A Metadata key that is not present is replaced by the empty string. Use {{
+// and }} for literal braces. For example,
+//	NewTemplateFormatter("{level} {time:2006-01-02} {file}:{line}] {message}")
+func NewTemplateFormatter(template string) (Formatter, error) {
+	var segments []templateSegment
+	var literal bytes.Buffer
+
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case c == '{' && i+1 < len(template) && template[i+1] == '{':
+			literal.WriteByte('{')
+			i++
+		case c == '}' && i+1 < len(template) && template[i+1] == '}':
+			literal.WriteByte('}')
+			i++
+		case c == '}':
+			return nil, fmt.Errorf("unmatched '}' at offset %d in template %q",
+				i, template)
+		case c == '{':
+			end := strings.Index(template[i:], "}")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated field at offset %d in template %q",
+					i, template)
+			}
+			segment, err := parseTemplateField(template[i+1 : i+end])
+			if err != nil {
+				return nil, err
+			}
+			if literal.Len() > 0 {
+				segments = append(segments,
+					templateSegment{literal: literal.String()})
+				literal.Reset()
+			}
+			segments = append(segments, segment)
+			i += end
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	if literal.Len() > 0 {
+		segments = append(segments, templateSegment{literal: literal.String()})
+	}
+
+	referenced := make(map[string]bool)
+	for _, segment := range segments {
+		if segment.field != "" {
+			referenced[segment.field] = true
+		}
+	}
+
+	return &templateFormatter{segments, referenced}, nil
+}
+
+// Parses the contents of a field, without the surrounding braces.
+func parseTemplateField(field string) (templateSegment, error) {
+	name, layout := field, ""
+	if colon := strings.Index(field, ":"); colon >= 0 {
+		name, layout = field[:colon], field[colon+1:]
+		if name != "time" {
+			return templateSegment{}, fmt.Errorf(
+				"only the time field takes a layout, got {%s}", field)
+		}
+	}
+	if name == "" {
+		return templateSegment{}, fmt.Errorf("empty field in template")
+	}
+	if name == "time" && layout == "" {
+		layout = defaultTemplateTimeLayout
+	}
+	return templateSegment{field: name, layout: layout}, nil
+}
+
+func (f *templateFormatter) Format(m *LogMessage) []byte {
+	var buf bytes.Buffer
+
+	for _, segment := range f.segments {
+		switch segment.field {
+		case "":
+			buf.WriteString(segment.literal)
+		case "level":
+			buf.WriteString(levelName(m.Level))
+		case "levelnum":
+			buf.WriteString(strconv.Itoa(m.Level))
+		case "time":
+			buf.WriteString(m.Nanoseconds.Format(segment.layout))
+		case "message":
+			buf.WriteString(m.Message)
+		case "fields":
+			renderFields(&buf, m, f.referenced)
+		default:
+			buf.WriteString(m.Metadata[segment.field])
+		}
+	}
+	return buf.Bytes()
+}
+
+// Returns a LogOuter that writes each LogMessage to the io.Writer according
+// to the template (see NewTemplateFormatter), or an error if the template
+// cannot be parsed.
+func NewTemplateWriterLogOuter(w io.Writer, template string) (LogOuter, error) {
+	if formatter, err := NewTemplateFormatter(template); err != nil {
+		return nil, err
+	} else {
+		return NewWriterLogOuterWithFormatter(w, formatter), nil
+	}
+
+	panic("Code never reaches here, this mollifies the compiler.")
+}
@@ -0,0 +1,105 @@
+package golog
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// The verbosity set by the golog.v flag.
+type verbosityFlag struct {
+	level int
+}
+
+var verbosity = &verbosityFlag{}
+
+func init() {
+	flag.Var(verbosity, "golog.v",
+		"Enable V(n) logging for all n at or below this verbosity. "+
+			"Can be overridden per module with golog.vmodule")
+}
+
+func (v *verbosityFlag) Set(val string) bool {
+	if level, err := strconv.Atoi(val); err == nil {
+		v.level = level
+		return true
+	} else {
+		fmt.Println("Error setting flag: ", err)
+	}
+
+	return false
+}
+
+func (v *verbosityFlag) String() string {
+	return strconv.Itoa(v.level)
+}
+
+// A Verbose logs INFO messages if it is enabled, and does nothing otherwise.
+// It is returned by V and PackageLogger.V. For example,
+//	golog.V(2).Infof("Cache miss for %v", key)
+// To avoid evaluating the arguments at all when disabled, guard the call:
+//	if v := golog.V(2); v.Enabled() {
+//		v.Info("Cache contents:", cache.Dump())
+//	}
+// Messages are logged at INFO. They are output even if minloglevel is above
+// INFO, since enabling a verbosity asks for them explicitly.
+type Verbose struct {
+	// Nil if disabled.
+	logger *PackageLogger
+}
+
+// Returns whether messages logged with the Verbose will be logged.
+func (v Verbose) Enabled() bool {
+	return v.logger != nil
+}
+
+// Logs as Info() if the Verbose is enabled.
+func (v Verbose) Info(msg ...interface{}) {
+	if v.logger != nil {
+		logUnfiltered(v.logger.logger, INFO, printClosure(msg...), nil, 1)
+	}
+}
+
+// Logs as Infof() if the Verbose is enabled.
+func (v Verbose) Infof(fmt string, vals ...interface{}) {
+	if v.logger != nil {
+		logUnfiltered(v.logger.logger, INFO,
+			printfClosure(fmt, vals...), nil, 1)
+	}
+}
+
+// Logs as Infoc() if the Verbose is enabled.
+func (v Verbose) Infoc(closure func() string) {
+	if v.logger != nil {
+		logUnfiltered(v.logger.logger, INFO, closure, nil, 1)
+	}
+}
+
+// Logs as Infow() if the Verbose is enabled.
+func (v Verbose) Infow(msg string, keysAndValues ...interface{}) {
+	if v.logger != nil {
+		logUnfiltered(v.logger.logger, INFO, printClosure(msg),
+			makeFields(keysAndValues), 1)
+	}
+}
+
+// Returns a Verbose that is enabled if the level is at or below the verbosity
+// set by the golog.v flag, or the override for the caller set by the
+// golog.vmodule flag if there is one. Does not allocate.
+func (l *PackageLogger) V(level int) Verbose {
+	return l.v(level, 1)
+}
+
+// Skip 0 refers to the function calling this function.
+func (l *PackageLogger) v(level int, skip int) Verbose {
+	enabled := level <= verbosity.level
+	// An override for the caller replaces the verbosity.
+	if override, ok := vmodule.lookupVerbosity(skip + 1); ok {
+		enabled = level <= override
+	}
+
+	if enabled {
+		return Verbose{l}
+	}
+	return Verbose{}
+}
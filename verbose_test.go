@@ -0,0 +1,78 @@
+package golog
+
+import (
+	"testing"
+)
+
+func TestV(t *testing.T) {
+	logger, received := newChanLogger(1, INFO, NoLocation)
+
+	defer verbosity.Set("0")
+	defer vmodule.Set("")
+
+	logger.V(1).Info("disabled")
+	if len(received) != 0 {
+		t.Error("Message logged above the verbosity")
+	}
+
+	if !verbosity.Set("2") {
+		t.Fatal("Error setting verbosity")
+	}
+	logger.V(2).Infof("enabled %d", 2)
+	if m := receive(t, received); m.Message != "enabled 2" {
+		t.Errorf("Expected enabled 2, got %v", m)
+	}
+
+	if !vmodule.Set("verbose_test=v0") {
+		t.Fatal("Error setting vmodule")
+	}
+	if logger.V(1).Enabled() {
+		t.Error("Override of verbosity ignored")
+	}
+	if !vmodule.Set("nomatch=v0,verbose_test=v3") {
+		t.Fatal("Error setting vmodule")
+	}
+	if !logger.V(3).Enabled() || logger.V(4).Enabled() {
+		t.Error("Override of verbosity ignored")
+	}
+}
+
+func TestVDoesNotAllocate(t *testing.T) {
+	logger := NewPackageLogger(NewMultiLogOuter(), INFO, nil, NoLocation)
+
+	guarded := func() {
+		if v := logger.V(1); v.Enabled() {
+			v.Info("disabled")
+		}
+	}
+
+	if allocs := testing.AllocsPerRun(100, guarded); allocs != 0 {
+		t.Errorf("Disabled V allocated %v times", allocs)
+	}
+
+	if !vmodule.Set("nomatch=v2") {
+		t.Fatal("Error setting vmodule")
+	}
+	defer vmodule.Set("")
+	if allocs := testing.AllocsPerRun(100, guarded); allocs != 0 {
+		t.Errorf("Disabled V with vmodule allocated %v times", allocs)
+	}
+}
+
+func TestVIgnoresMinLogLevel(t *testing.T) {
+	logger, received := newChanLogger(2, ERROR, NoLocation)
+
+	if !verbosity.Set("2") {
+		t.Fatal("Error setting verbosity")
+	}
+	defer verbosity.Set("0")
+
+	logger.V(2).Info("enabled")
+	logger.With("k", "v").V(1).Infow("child")
+	if m := receive(t, received); m.Message != "enabled" {
+		t.Errorf("Expected enabled, got %v", m)
+	}
+	if m := receive(t, received); m.Message != "child" || m.Metadata["k"] != "v" {
+		t.Errorf("Expected child with k=v, got %v", m)
+	}
+}
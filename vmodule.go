@@ -0,0 +1,210 @@
+package golog
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A single pattern=level or pattern=vN override.
+type vmodulePattern struct {
+	pattern string
+	level   int
+	// True if the override is of the verbosity rather than minloglevel.
+	verbose bool
+}
+
+// The overrides matching a call site.
+type vmoduleMatch struct {
+	level        int
+	hasLevel     bool
+	verbosity    int
+	hasVerbosity bool
+}
+
+// The set of per module overrides of minloglevel and verbosity. A pattern is
+// matched (as by path.Match) against the base name of the file of the caller
+// without the .go extension, and against the full import path of the package
+// of the caller. The first pattern that matches wins, with overrides of
+// minloglevel and verbosity considered separately.
+type vmoduleFlag struct {
+	lock     sync.RWMutex
+	patterns []vmodulePattern
+	// The number of patterns that override the verbosity.
+	verbose int
+	// A cache from the pc of a call site to the matching overrides.
+	cache map[uintptr]vmoduleMatch
+}
+
+var vmodule = &vmoduleFlag{cache: make(map[uintptr]vmoduleMatch)}
+
+func init() {
+	flag.Var(vmodule, "golog.vmodule",
+		"Comma separated list of pattern=level overrides of "+
+			"minloglevel and pattern=vN overrides of golog.v, where "+
+			"pattern matches the file name (without .go) or package "+
+			"of the caller, for example "+
+			"\"server=DEBUG,github.com/me/db*=ERROR,client=v2\"")
+}
+
+func (v *vmoduleFlag) Set(val string) bool {
+	var patterns []vmodulePattern
+	verbose := 0
+
+	for _, spec := range strings.Split(val, ",") {
+		if spec == "" {
+			continue
+		}
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			fmt.Println("Error setting flag: expected pattern=level, got",
+				spec)
+			return false
+		}
+		if _, err := path.Match(parts[0], ""); err != nil {
+			fmt.Println("Error setting flag: ", err)
+			return false
+		}
+		if verbosity, ok := parseVerbosity(parts[1]); ok {
+			patterns = append(patterns,
+				vmodulePattern{parts[0], verbosity, true})
+			verbose++
+			continue
+		}
+		level, err := ParseLevel(parts[1])
+		if err != nil {
+			fmt.Println("Error setting flag: ", err)
+			return false
+		}
+		patterns = append(patterns, vmodulePattern{parts[0], level, false})
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	v.patterns = patterns
+	v.verbose = verbose
+	v.cache = make(map[uintptr]vmoduleMatch)
+	return true
+}
+
+func (v *vmoduleFlag) String() string {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
+	var buf bytes.Buffer
+	for i, p := range v.patterns {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		if p.verbose {
+			fmt.Fprintf(&buf, "%s=v%d", p.pattern, p.level)
+		} else {
+			fmt.Fprintf(&buf, "%s=%s", p.pattern, levelName(p.level))
+		}
+	}
+	return buf.String()
+}
+
+// Parses a verbosity override of the form vN, such as "v2".
+func parseVerbosity(val string) (int, bool) {
+	if len(val) < 2 || (val[0] != 'v' && val[0] != 'V') {
+		return 0, false
+	}
+	verbosity, err := strconv.Atoi(val[1:])
+	if err != nil {
+		return 0, false
+	}
+	return verbosity, true
+}
+
+// Returns the overrides matching the call site with the return address pc.
+// Must be called with the lock held.
+func (v *vmoduleFlag) match(pc uintptr) vmoduleMatch {
+	name := lookupFuncName(pc - 1)
+	module, pkg := "", name.pkg
+	if name.file != "" {
+		module = path.Base(name.file)
+		if strings.HasSuffix(module, ".go") {
+			module = module[:len(module)-len(".go")]
+		}
+	}
+
+	var m vmoduleMatch
+	for _, p := range v.patterns {
+		if p.verbose && m.hasVerbosity || !p.verbose && m.hasLevel {
+			continue
+		}
+		if matched, _ := path.Match(p.pattern, module); !matched {
+			if matched, _ = path.Match(p.pattern, pkg); !matched {
+				continue
+			}
+		}
+		if p.verbose {
+			m.verbosity, m.hasVerbosity = p.level, true
+		} else {
+			m.level, m.hasLevel = p.level, true
+		}
+	}
+	return m
+}
+
+// Returns the overrides matching the caller skip frames up. Skip 0 refers to
+// the function calling this function. Must be called with the read lock held,
+// which may be released and reacquired. Cached per call site.
+func (v *vmoduleFlag) find(skip int) vmoduleMatch {
+	// Unlike runtime.Caller, runtime.Callers does not allocate.
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return vmoduleMatch{}
+	}
+	pc := pcs[0]
+
+	if m, cached := v.cache[pc]; cached {
+		return m
+	}
+	v.lock.RUnlock()
+	v.lock.Lock()
+
+	// The patterns may have changed while we didn't hold the lock.
+	m := v.match(pc)
+	v.cache[pc] = m
+
+	v.lock.Unlock()
+	v.lock.RLock()
+	return m
+}
+
+// Returns the overridden minloglevel for the caller skip frames up, and
+// whether there is an override. Skip 0 refers to the function calling this
+// function. Cheap if there are no overrides, and cached per call site
+// otherwise.
+func (v *vmoduleFlag) lookup(skip int) (int, bool) {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
+	if len(v.patterns) == v.verbose {
+		return 0, false
+	}
+	m := v.find(skip + 1)
+	return m.level, m.hasLevel
+}
+
+// Returns the overridden verbosity for the caller skip frames up, and whether
+// there is an override. Skip 0 refers to the function calling this function.
+// Cheap if there are no overrides, and cached per call site otherwise.
+func (v *vmoduleFlag) lookupVerbosity(skip int) (int, bool) {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
+	if v.verbose == 0 {
+		return 0, false
+	}
+	m := v.find(skip + 1)
+	return m.verbosity, m.hasVerbosity
+}
@@ -0,0 +1,32 @@
+package golog
+
+import (
+	"testing"
+)
+
+func TestVmodule(t *testing.T) {
+	logger, received := newChanLogger(1, ERROR, NoLocation)
+
+	if !vmodule.Set("nomatch=FATAL,vmodule_t?st=DEBUG") {
+		t.Fatal("Error setting vmodule")
+	}
+	defer vmodule.Set("")
+
+	logger.Debug("more verbose")
+	if m := receive(t, received); m.Message != "more verbose" {
+		t.Errorf("Expected more verbose, got %v", m)
+	}
+
+	logger.Trace("still filtered")
+	if len(received) != 0 {
+		t.Error("Message logged below the overridden level")
+	}
+
+	if !vmodule.Set(testPackage + "=FATAL") {
+		t.Fatal("Error setting vmodule")
+	}
+	logger.Error("less verbose")
+	if len(received) != 0 {
+		t.Error("Message logged below the overridden level")
+	}
+}